@@ -0,0 +1,191 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"reflect"
+	"sync"
+)
+
+// aliasMap holds the aliases of a container. It maps an alias ref to its target ref.
+type aliasMap struct {
+	mu      sync.RWMutex
+	aliases map[fmt.Stringer]fmt.Stringer
+}
+
+func newAliasMap() *aliasMap {
+	return &aliasMap{
+		mu:      sync.RWMutex{},
+		aliases: map[fmt.Stringer]fmt.Stringer{},
+	}
+}
+
+func (m *aliasMap) store(alias fmt.Stringer, target fmt.Stringer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.aliases[alias] = target
+}
+
+func (m *aliasMap) load(alias fmt.Stringer) (fmt.Stringer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	target, ok := m.aliases[alias]
+
+	return target, ok
+}
+
+func (m *aliasMap) copyTo(aliases map[fmt.Stringer]fmt.Stringer) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for alias, target := range m.aliases {
+		aliases[alias] = target
+	}
+}
+
+// typeRef is the ref a type is bound to a service with.
+type typeRef struct {
+	t reflect.Type
+}
+
+// String implements fmt.Stringer interface method.
+func (r typeRef) String() string {
+	return fmt.Sprintf("type:%s", r.t)
+}
+
+// Alias registers aliasRef as an additional name for the service targetRef.
+// Requesting the alias returns the target service. An alias may point to another alias.
+// Aliases take precedence over services registered with the same ref.
+func (c *Container) Alias(aliasRef fmt.Stringer, targetRef fmt.Stringer) *Container {
+	c.aliases.store(aliasRef, targetRef)
+
+	return c
+}
+
+// Bind binds a type, usually an interface, to the service ref.
+// The service can be requested by type via GetByType, and autowiring uses the bound service for parameters of
+// this type even if other services are assignable to it as well.
+func (c *Container) Bind(t reflect.Type, ref fmt.Stringer) *Container {
+	return c.Alias(typeRef{t: t}, ref)
+}
+
+// GetByType returns the service that is bound to given type.
+func (c *Container) GetByType(t reflect.Type) (interface{}, error) {
+	if _, ok := c.lookupAlias(typeRef{t: t}); !ok {
+		return nil, z.NewWithOpts(fmt.Sprintf("no service bound to type %s", t), z.WithType(ServiceNotFoundError))
+	}
+
+	return c.Get(typeRef{t: t})
+}
+
+// lookupAlias returns the target of an alias defined in the container or one of its parents.
+func (c *Container) lookupAlias(alias fmt.Stringer) (fmt.Stringer, bool) {
+	for container := c; container != nil; container = container.parent {
+		if target, ok := container.aliases.load(alias); ok {
+			return target, true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveAlias follows aliases starting at ref and returns the ref of the service they point to.
+// Refs that are not an alias are returned unchanged.
+func (c *Container) resolveAlias(ref fmt.Stringer) (fmt.Stringer, error) {
+	ref = c.resolveNameRef(ref)
+	chain := []fmt.Stringer{ref}
+	seen := map[fmt.Stringer]bool{ref: true}
+
+	for {
+		target, ok := c.lookupAlias(ref)
+		if !ok {
+			return ref, nil
+		}
+
+		chain = append(chain, target)
+
+		if seen[target] {
+			return nil, z.NewWithOpts(
+				fmt.Sprintf("circular alias detected: %s", formatRefChain(chain)),
+				z.WithType(CircularDependencyError),
+			)
+		}
+
+		seen[target] = true
+		ref = target
+	}
+}
+
+// canonicalRef returns the ref of the service given ref points to. On alias errors ref is returned unchanged.
+func (c *Container) canonicalRef(ref fmt.Stringer) fmt.Stringer {
+	if target, err := c.resolveAlias(ref); err == nil {
+		return target
+	}
+
+	return ref
+}
+
+// allAliases returns all aliases that are visible to the container, including the inherited ones.
+func (c *Container) allAliases() map[fmt.Stringer]fmt.Stringer {
+	aliases := map[fmt.Stringer]fmt.Stringer{}
+
+	if c.parent != nil {
+		aliases = c.parent.allAliases()
+	}
+
+	c.aliases.copyTo(aliases)
+
+	return aliases
+}
+
+// aliasRefs returns the refs of all aliases that are visible to the container.
+func (c *Container) aliasRefs() []fmt.Stringer {
+	aliases := c.allAliases()
+
+	refs := make([]fmt.Stringer, 0, len(aliases))
+	for alias := range aliases {
+		refs = append(refs, alias)
+	}
+
+	return refs
+}
+
+// validateAlias checks that an alias points to a registered service of a matching type.
+func (c *Container) validateAlias(alias fmt.Stringer) (errs []error) {
+	target, err := c.resolveAlias(alias)
+	if err != nil {
+		return append(errs, err)
+	}
+
+	if _, ok := c.serviceDefs.Load(alias); ok {
+		errs = append(errs, z.NewWithOpts(
+			fmt.Sprintf("alias %s shadows a registered service", alias),
+			z.WithType(ContainerValidationError),
+		))
+	}
+
+	def, ok := c.loadServiceDef(target)
+	if !ok {
+		return append(errs, aliasTargetNotFound(alias, target))
+	}
+
+	if ref, ok := alias.(typeRef); ok {
+		if serviceType := c.serviceType(def); serviceType != nil && !serviceType.AssignableTo(ref.t) {
+			errs = append(errs, z.NewWithOpts(
+				fmt.Sprintf("service %s of type %s is not assignable to %s", target, serviceType, ref.t),
+				z.WithType(ServiceTypeMismatchError),
+			))
+		}
+	}
+
+	return errs
+}
+
+func aliasTargetNotFound(alias fmt.Stringer, target fmt.Stringer) error {
+	return z.NewWithOpts(
+		fmt.Sprintf("alias %s points to service %s which is not registered", alias, target),
+		z.WithType(ServiceNotFoundError),
+	)
+}
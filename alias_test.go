@@ -0,0 +1,149 @@
+package di_test
+
+import (
+	"context"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestContainer_Alias(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("zap.logger")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "zap"} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("consumer")).
+			Provider(func(logger *closeRecorder) string {
+				return logger.Name()
+			}).
+			Args(di.ServiceArg(di.StringRef("logger"))),
+	)
+
+	container.
+		Alias(di.StringRef("logger"), di.StringRef("default.logger")).
+		Alias(di.StringRef("default.logger"), di.StringRef("zap.logger"))
+
+	assert.NoError(t, container.Validate())
+
+	logger, err := container.Get(di.StringRef("logger"))
+	assert.NoError(t, err)
+
+	target, err := container.Get(di.StringRef("zap.logger"))
+	assert.NoError(t, err)
+	assert.Same(t, target, logger)
+
+	consumer, err := di.Get[string](container, di.StringRef("consumer"))
+	assert.NoError(t, err)
+	assert.Equal(t, "zap", consumer)
+}
+
+func TestContainer_Alias_MissingTarget(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Alias(di.StringRef("logger"), di.StringRef("zap.logger"))
+
+	_, err := container.Get(di.StringRef("logger"))
+	assertErrorType(t, err, di.ServiceNotFoundError)
+	assert.Contains(t, err.Error(), "alias logger points to service zap.logger which is not registered")
+
+	err = container.Validate()
+	assertErrorType(t, err, di.ContainerValidationError)
+	assert.Contains(t, err.Error(), "alias logger points to service zap.logger which is not registered")
+}
+
+func TestContainer_Alias_Circular(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.
+		Alias(di.StringRef("a"), di.StringRef("b")).
+		Alias(di.StringRef("b"), di.StringRef("a"))
+
+	_, err := container.Get(di.StringRef("a"))
+	assertErrorType(t, err, di.CircularDependencyError)
+	assert.Contains(t, err.Error(), "circular alias detected: a -> b -> a")
+}
+
+func TestContainer_Alias_Scope(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("request")).
+			Opts(di.Scoped()).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "request"} //nolint:exhaustivestruct
+			}),
+	)
+	container.Alias(di.StringRef("current.request"), di.StringRef("request"))
+
+	scope := container.NewScope(context.Background())
+
+	aliased, err := scope.Get(di.StringRef("current.request"))
+	assert.NoError(t, err)
+
+	request, err := scope.Get(di.StringRef("request"))
+	assert.NoError(t, err)
+	assert.Same(t, request, aliased)
+}
+
+func TestContainer_Bind(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("service1")).
+			Provider(NewTestService1).
+			Args(di.ContextArg(), di.ContainerArg(), di.InterfaceArg(true), di.InterfaceArg("foo")),
+		di.NewServiceDef(di.StringRef("service2")).
+			Provider(func(service1 TestInterface) *TestService2 {
+				return NewTestService2(service1, nil, false, "", "")
+			}).
+			Opts(di.Autowire()),
+	)
+
+	// both services implement TestInterface, the binding resolves the ambiguity.
+	di.Bind[TestInterface](container, di.StringRef("service1"))
+
+	assert.NoError(t, container.Validate())
+
+	bound, err := di.GetByType[TestInterface](container)
+	assert.NoError(t, err)
+	assert.IsType(t, &TestService1{}, bound)
+
+	instance, err := container.GetByType(reflect.TypeOf((*TestInterface)(nil)).Elem())
+	assert.NoError(t, err)
+	assert.Same(t, bound, instance)
+
+	service2, err := di.Get[*TestService2](container, di.StringRef("service2"))
+	assert.NoError(t, err)
+	assert.Same(t, bound, service2.TestService1())
+}
+
+func TestContainer_Bind_Errors(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Set(di.StringRef("foo"), "foo")
+
+	_, err := di.GetByType[TestInterface](container)
+	assertErrorType(t, err, di.ServiceNotFoundError)
+
+	di.Bind[TestInterface](container, di.StringRef("foo"))
+
+	err = container.Validate()
+	assertErrorType(t, err, di.ContainerValidationError)
+	assert.Contains(t, err.Error(), "service foo of type string is not assignable to di_test.TestInterface")
+}
+
+func TestContainer_Graph_Alias(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Set(di.StringRef("zap.logger"), "zap")
+	container.Alias(di.StringRef("logger"), di.StringRef("zap.logger"))
+	container.Alias(di.StringRef("missing"), di.StringRef("nothing"))
+
+	graph := container.Graph()
+
+	aliasNode := di.GraphNode{ID: "logger", Kind: di.GraphNodeAlias}                     //nolint:exhaustivestruct
+	missingNode := di.GraphNode{ID: "nothing", Kind: di.GraphNodeMissing}                //nolint:exhaustivestruct
+	aliasEdge := di.GraphEdge{From: "logger", To: "zap.logger", Kind: di.GraphEdgeAlias} //nolint:exhaustivestruct
+
+	assert.Contains(t, graph.Nodes, aliasNode)
+	assert.Contains(t, graph.Nodes, missingNode)
+	assert.Contains(t, graph.Edges, aliasEdge)
+	assert.Contains(t, graph.DOT(), "\"logger\" [label=\"logger\\nalias\", shape=ellipse];")
+}
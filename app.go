@@ -0,0 +1,200 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di/internal/pkg/utils"
+	z "github.com/dtomasi/zerrors"
+	"github.com/hashicorp/go-multierror"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is the time an App waits for runners and services to shut down.
+const defaultShutdownTimeout = 30 * time.Second
+
+// Runnable is implemented by services that run for the lifetime of an App, e.g. servers or workers.
+// Run has to block until ctx is cancelled or the service fails.
+type Runnable interface {
+	Run(ctx context.Context) error
+}
+
+// AppOption defines an option function for NewApp and Run.
+type AppOption func(a *App)
+
+// WithShutdownTimeout defines how long the App waits for runners to return and, separately, for the container to
+// close.
+func WithShutdownTimeout(timeout time.Duration) AppOption {
+	return func(a *App) {
+		a.shutdownTimeout = timeout
+	}
+}
+
+// WithSignals defines the signals that shut down the App. Defaults to SIGINT and SIGTERM.
+func WithSignals(signals ...os.Signal) AppOption {
+	return func(a *App) {
+		a.signals = signals
+	}
+}
+
+// WithAppBuildOptions defines the options that are used to build the container.
+func WithAppBuildOptions(opts ...BuildOption) AppOption {
+	return func(a *App) {
+		a.buildOptions = opts
+	}
+}
+
+// App runs the services of a container until the App is shut down.
+type App struct {
+	container       *Container
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+	buildOptions    []BuildOption
+}
+
+// NewApp returns an App for given container.
+func NewApp(c *Container, opts ...AppOption) *App {
+	a := &App{
+		container:       c,
+		shutdownTimeout: defaultShutdownTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		buildOptions:    nil,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Run builds the container and runs a NewApp with given options. See App.Run for details.
+func Run(ctx context.Context, c *Container, opts ...AppOption) error {
+	return NewApp(c, opts...).Run(ctx)
+}
+
+// Run builds the container and calls Run of all built services implementing Runnable in their own goroutine.
+// The App shuts down if one of the signals is received, ctx or the container context is cancelled, a runner
+// fails or all runners returned. On shutdown the runners and the container context are cancelled, and the
+// container is closed once all runners returned. Waiting for the runners and closing the container each get
+// their own shutdown timeout, so runners that ignore the cancellation do not prevent the services from stopping.
+// Errors of the runners and of closing the container are returned together.
+func (a *App) Run(ctx context.Context) (err error) {
+	c := a.container
+
+	if err = c.Build(a.buildOptions...); err != nil {
+		return a.close(err)
+	}
+
+	ctx, stopSignals := signal.NotifyContext(ctx, a.signals...)
+	defer stopSignals()
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+
+	runners := a.runners()
+	errs := make(chan error, len(runners))
+	wg := sync.WaitGroup{}
+
+	for _, ref := range runners {
+		def, _ := c.serviceDefs.Load(ref)
+
+		wg.Add(1)
+
+		go func(ref fmt.Stringer, runner Runnable) {
+			defer wg.Done()
+
+			c.logger.V(utils.LogLevelDebug).Info("running service", "name", ref.String())
+
+			if runErr := runner.Run(runCtx); runErr != nil {
+				errs <- z.WrapWithOpts(runErr,
+					fmt.Sprintf("error while running service %s", ref),
+					z.WithType(ServiceRunError),
+				)
+			}
+		}(ref, def.getInstance().(Runnable)) //nolint:forcetypeassert
+	}
+
+	returned := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(returned)
+	}()
+
+	// without runners the App runs until it is shut down.
+	allReturned := returned
+	if len(runners) == 0 {
+		allReturned = nil
+	}
+
+	select {
+	case <-ctx.Done():
+		c.logger.V(utils.LogLevelDebug).Info("shutting down app")
+	case <-c.ctx.Done():
+		c.logger.V(utils.LogLevelDebug).Info("shutting down app because the container context was cancelled")
+	case runErr := <-errs:
+		err = multierror.Append(err, runErr)
+	case <-allReturned:
+		c.logger.V(utils.LogLevelDebug).Info("shutting down app because all runners returned")
+	}
+
+	return a.shutdown(cancelRun, returned, errs, err)
+}
+
+// shutdown cancels the runners and the container context and closes the container once all runners returned.
+func (a *App) shutdown(cancelRun context.CancelFunc, returned chan struct{}, errs chan error, err error) error {
+	c := a.container
+
+	cancelRun()
+	c.CancelContext()
+
+	timer := time.NewTimer(a.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-returned:
+	case <-timer.C:
+		err = multierror.Append(err, z.NewWithOpts(
+			fmt.Sprintf("runners did not return within %s", a.shutdownTimeout),
+			z.WithType(ServiceRunError),
+		))
+	}
+
+	// collect the errors of runners that failed while shutting down.
+	for len(errs) > 0 {
+		err = multierror.Append(err, <-errs)
+	}
+
+	return a.close(err)
+}
+
+// close closes the container within the shutdown timeout and appends the error of closing to err.
+func (a *App) close(err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	if closeErr := a.container.Close(ctx); closeErr != nil {
+		err = multierror.Append(err, closeErr)
+	}
+
+	return err
+}
+
+// runners returns the refs of all built services implementing Runnable in dependency order.
+func (a *App) runners() []fmt.Stringer {
+	var refs []fmt.Stringer
+
+	_ = a.container.serviceDefs.Range(func(key fmt.Stringer, def *ServiceDef) error {
+		if _, ok := def.getInstance().(Runnable); ok && def.provider != nil && !def.options.alwaysRebuild {
+			refs = append(refs, key)
+		}
+
+		return nil
+	})
+
+	return a.container.sortByDependencies(refs)
+}
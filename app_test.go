@@ -0,0 +1,111 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type worker struct {
+	run     func(ctx context.Context) error
+	stopped bool
+}
+
+func (w *worker) Run(ctx context.Context) error {
+	return w.run(ctx)
+}
+
+func (w *worker) Stop(_ context.Context) error {
+	w.stopped = true
+
+	return nil
+}
+
+func untilCancelled(ctx context.Context) error {
+	<-ctx.Done()
+
+	return nil
+}
+
+func newAppContainer(workers map[string]*worker) *di.Container {
+	container := di.NewServiceContainer()
+
+	for name, w := range workers {
+		w := w
+
+		container.Register(
+			di.NewServiceDef(di.StringRef(name)).
+				Provider(func() *worker { return w }),
+		)
+	}
+
+	return container
+}
+
+func TestRun_ContextCancelled(t *testing.T) {
+	w := &worker{run: untilCancelled}
+	container := newAppContainer(map[string]*worker{"worker": w})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	assert.NoError(t, di.Run(ctx, container))
+	assert.True(t, w.stopped)
+}
+
+func TestRun_RunnerFails(t *testing.T) {
+	cancelled := &worker{run: untilCancelled}
+	failing := &worker{run: func(_ context.Context) error {
+		return errors.New("connection lost")
+	}}
+	container := newAppContainer(map[string]*worker{"cancelled": cancelled, "failing": failing})
+
+	err := di.Run(context.Background(), container)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error while running service failing")
+	assert.Contains(t, err.Error(), "connection lost")
+	assert.True(t, cancelled.stopped)
+	assert.True(t, failing.stopped)
+}
+
+func TestRun_AllRunnersReturned(t *testing.T) {
+	w := &worker{run: func(_ context.Context) error {
+		return nil
+	}}
+	container := newAppContainer(map[string]*worker{"worker": w})
+
+	assert.NoError(t, di.Run(context.Background(), container))
+	assert.True(t, w.stopped)
+}
+
+func TestRun_ShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	w := &worker{run: func(_ context.Context) error {
+		<-release
+
+		return nil
+	}}
+	container := newAppContainer(map[string]*worker{"worker": w})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := di.NewApp(container, di.WithShutdownTimeout(10*time.Millisecond)).Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "runners did not return within 10ms")
+	// the container is closed with its own timeout after the runners timed out
+	assert.True(t, w.stopped)
+}
+
+func TestRun_BuildError(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(di.NewServiceDef(di.StringRef("broken")))
+
+	err := di.Run(context.Background(), container)
+	assertErrorType(t, err, di.ContainerBuildError)
+}
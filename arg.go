@@ -2,7 +2,9 @@ package di
 
 import (
 	"fmt"
+	z "github.com/dtomasi/zerrors"
 	"reflect"
+	"strings"
 )
 
 // ServiceDefArg is the interface that arguments have to im.
@@ -18,6 +20,10 @@ func (a *interfaceArg) Evaluate(_ *Container) (interface{}, error) {
 	return a.inValue, nil
 }
 
+func (a *interfaceArg) staticType(_ *Container) reflect.Type {
+	return reflect.TypeOf(a.inValue)
+}
+
 // InterfaceArg is a shortcut for an argument of type interface{}.
 // This argument allows to pass any value that is provided to the service.
 func InterfaceArg(in interface{}) ServiceDefArg {
@@ -27,15 +33,55 @@ func InterfaceArg(in interface{}) ServiceDefArg {
 // ServiceRef Arg
 // This argument type allows to pass a reference to a service that will be injected.
 type serviceRefArg struct {
-	ref fmt.Stringer
+	ref      fmt.Stringer
+	optional bool
 }
 
 func (a *serviceRefArg) Evaluate(c *Container) (interface{}, error) {
-	return c.Get(a.ref)
+	if _, ok := c.loadServiceDef(a.ref); !ok && a.optional {
+		return nil, nil
+	}
+
+	return c.get(a.ref)
+}
+
+func (a *serviceRefArg) dependencies(_ *Container) []fmt.Stringer {
+	return []fmt.Stringer{a.ref}
+}
+
+func (a *serviceRefArg) graphEdges(_ *Container, from string) []GraphEdge {
+	edge := GraphEdge{From: from, To: a.ref.String(), Kind: GraphEdgeService, Label: ""}
+	if a.optional {
+		edge.Label = "optional"
+	}
+
+	return []GraphEdge{edge}
+}
+
+func (a *serviceRefArg) validate(c *Container) []error {
+	if a.optional {
+		return nil
+	}
+
+	if err := c.validateServiceRef(a.ref); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func (a *serviceRefArg) staticType(c *Container) reflect.Type {
+	return c.staticServiceType(a.ref)
 }
 
 func ServiceArg(ref fmt.Stringer) ServiceDefArg {
-	return &serviceRefArg{ref: ref}
+	return &serviceRefArg{ref: ref, optional: false}
+}
+
+// OptionalServiceArg is a service argument that injects nil if the service is not registered.
+// Errors while building a registered service are still returned.
+func OptionalServiceArg(ref fmt.Stringer) ServiceDefArg {
+	return &serviceRefArg{ref: ref, optional: true}
 }
 
 // Service Method Call Argument allows to use the return value of a method call on given service.
@@ -46,7 +92,7 @@ type serviceMethodCallArg struct {
 }
 
 func (a *serviceMethodCallArg) Evaluate(c *Container) (interface{}, error) {
-	s, err := c.Get(a.serviceRef)
+	s, err := c.get(a.serviceRef)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +100,87 @@ func (a *serviceMethodCallArg) Evaluate(c *Container) (interface{}, error) {
 	return c.callReflectValueWithArgs(reflect.ValueOf(s).MethodByName(a.methodName), a.args)
 }
 
+func (a *serviceMethodCallArg) dependencies(c *Container) []fmt.Stringer {
+	return append([]fmt.Stringer{a.serviceRef}, argDependencies(c, a.args)...)
+}
+
+func (a *serviceMethodCallArg) paramKeys(c *Container) []string {
+	return argParamKeys(c, a.args)
+}
+
+func (a *serviceMethodCallArg) graphEdges(c *Container, from string) []GraphEdge {
+	return append(
+		[]GraphEdge{{From: from, To: a.serviceRef.String(), Kind: GraphEdgeMethodCall, Label: a.methodName + "()"}},
+		argGraphEdges(c, from, a.args)...,
+	)
+}
+
+func (a *serviceMethodCallArg) validate(c *Container) []error {
+	if err := c.validateServiceRef(a.serviceRef); err != nil {
+		return []error{err}
+	}
+
+	methodType, err := a.methodType(c)
+	if err != nil {
+		return []error{err}
+	}
+
+	if methodType != nil && methodType.NumIn() != len(a.args) {
+		return []error{z.NewWithOpts(
+			fmt.Sprintf("method %s expects %d args got %d", a.methodName, methodType.NumIn(), len(a.args)),
+			z.WithType(CallableArgCountMismatchError),
+		)}
+	}
+
+	return c.validateArgs(a.args, methodType)
+}
+
+func (a *serviceMethodCallArg) staticType(c *Container) reflect.Type {
+	if methodType, _ := a.methodType(c); methodType != nil && methodType.NumOut() > 0 {
+		return methodType.Out(0)
+	}
+
+	return nil
+}
+
+// methodType returns the type of the called method without receiver if the type of the service is known.
+func (a *serviceMethodCallArg) methodType(c *Container) (reflect.Type, error) {
+	return methodType(c.staticServiceType(a.serviceRef), a.methodName)
+}
+
+// methodType returns the type of the named method of serviceType without receiver.
+// It returns nil if serviceType is nil.
+func methodType(serviceType reflect.Type, methodName string) (reflect.Type, error) {
+	if serviceType == nil {
+		return nil, nil
+	}
+
+	method, ok := serviceType.MethodByName(methodName)
+	if !ok {
+		return nil, z.NewWithOpts(
+			fmt.Sprintf("method %s not found on %s", methodName, serviceType),
+			z.WithType(CallableNotAFuncError),
+		)
+	}
+
+	// methods of interface types are returned without receiver
+	if serviceType.Kind() == reflect.Interface {
+		return method.Type, nil
+	}
+
+	in := make([]reflect.Type, 0, method.Type.NumIn()-1)
+	for i := 1; i < method.Type.NumIn(); i++ {
+		in = append(in, method.Type.In(i))
+	}
+
+	out := make([]reflect.Type, 0, method.Type.NumOut())
+	for i := 0; i < method.Type.NumOut(); i++ {
+		out = append(out, method.Type.Out(i))
+	}
+
+	return reflect.FuncOf(in, out, method.Type.IsVariadic()), nil
+}
+
 func ServiceMethodCallArg(serviceRef fmt.Stringer, methodName string, args ...ServiceDefArg) ServiceDefArg {
 	return &serviceMethodCallArg{
 		serviceRef: serviceRef,
@@ -71,6 +198,25 @@ func (a *servicesByTagArg) Evaluate(c *Container) (interface{}, error) {
 	return c.FindByTags(a.tags)
 }
 
+func (a *servicesByTagArg) dependencies(c *Container) []fmt.Stringer {
+	return c.findRefsByTags(a.tags)
+}
+
+func (a *servicesByTagArg) staticType(_ *Container) reflect.Type {
+	return reflect.TypeOf([]interface{}{})
+}
+
+func (a *servicesByTagArg) graphEdges(c *Container, from string) []GraphEdge {
+	var edges []GraphEdge
+
+	label := "tags: " + strings.Join(refNames(a.tags), ", ")
+	for _, ref := range c.findRefsByTags(a.tags) {
+		edges = append(edges, GraphEdge{From: from, To: ref.String(), Kind: GraphEdgeTagged, Label: label})
+	}
+
+	return edges
+}
+
 // ServicesByTagsArg is a shortcut for a service argument.
 //goland:noinspection GoUnusedExportedFunction
 func ServicesByTagsArg(tags []fmt.Stringer) ServiceDefArg {
@@ -79,15 +225,165 @@ func ServicesByTagsArg(tags []fmt.Stringer) ServiceDefArg {
 
 // Parameter Argument allows to get parameters by path/dot notation from parameter provider.
 type paramArg struct {
-	paramPath string
+	paramPath  string
+	def        interface{}
+	hasDefault bool
 }
 
 func (a *paramArg) Evaluate(c *Container) (interface{}, error) {
-	return c.paramProvider.Get(a.paramPath)
+	return c.getParam(a.paramPath, a.def, a.hasDefault)
+}
+
+func (a *paramArg) convert(value interface{}, targetType reflect.Type) (interface{}, error) {
+	converted, err := convertParam(value, targetType)
+	if err != nil {
+		return nil, z.Wrapf(err, "invalid parameter %s", a.paramPath)
+	}
+
+	return converted, nil
+}
+
+func (a *paramArg) paramKeys(c *Container) []string {
+	return c.paramKeysOf(a.paramPath, map[string]bool{})
+}
+
+func (a *paramArg) graphEdges(_ *Container, from string) []GraphEdge {
+	return []GraphEdge{{From: from, To: graphParamPrefix + a.paramPath, Kind: GraphEdgeParam, Label: ""}}
 }
 
+func (a *paramArg) validate(c *Container) []error {
+	if _, err := a.Evaluate(c); err != nil {
+		return []error{z.Wrapf(err, "parameter %s cannot be resolved", a.paramPath)}
+	}
+
+	return nil
+}
+
+// ParamArg is an argument that is resolved by the ParameterProvider of the container.
+// Placeholders in string values are resolved if enabled by WithParamInterpolation, see StringArg.
+// The value is converted to the type of the provider parameter, see ParamArgAs for supported conversions.
 func ParamArg(paramPath string) ServiceDefArg {
-	return &paramArg{paramPath: paramPath}
+	return &paramArg{paramPath: paramPath, def: nil, hasDefault: false}
+}
+
+// ParamArgWithDefault is a parameter argument that falls back to given value if the ParameterProvider cannot
+// provide the parameter. Like parameter values, the default value is converted to the type of the provider
+// parameter.
+func ParamArgWithDefault(paramPath string, value interface{}) ServiceDefArg {
+	return &paramArg{paramPath: paramPath, def: value, hasDefault: true}
+}
+
+// OptionalParamArg is a parameter argument that injects the zero value of the provider parameter type if the
+// ParameterProvider cannot provide the parameter.
+func OptionalParamArg(paramPath string) ServiceDefArg {
+	return &paramArg{paramPath: paramPath, def: nil, hasDefault: true}
+}
+
+// typedParamArg is a parameter argument that is converted to a type that is known when defining the argument.
+type typedParamArg struct {
+	paramArg
+	targetType reflect.Type
+}
+
+func (a *typedParamArg) Evaluate(c *Container) (interface{}, error) {
+	value, err := a.paramArg.Evaluate(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.paramArg.convert(value, a.targetType)
+}
+
+// convert enforces the declared type: the value is already converted to it and has to match the parameter type
+// of the provider as it does in validate.
+func (a *typedParamArg) convert(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if err := validateType(a.targetType, targetType); err != nil {
+		return nil, z.Wrapf(err, "invalid parameter %s", a.paramPath)
+	}
+
+	return value, nil
+}
+
+func (a *typedParamArg) validate(c *Container) []error {
+	if _, err := a.Evaluate(c); err != nil {
+		return []error{z.Wrapf(err, "parameter %s cannot be resolved", a.paramPath)}
+	}
+
+	return nil
+}
+
+func (a *typedParamArg) staticType(_ *Container) reflect.Type {
+	return a.targetType
+}
+
+// ParamArgAs is a parameter argument whose value is converted to T.
+// Strings are parsed into numbers, bools, time.Duration and types implementing encoding.TextUnmarshaler.
+// Numbers are converted into other number types if the value fits. A string is split at "," into a slice and
+// "key=value" pairs separated by "," are converted into a map.
+func ParamArgAs[T any](paramPath string) ServiceDefArg {
+	return &typedParamArg{
+		paramArg:   paramArg{paramPath: paramPath, def: nil, hasDefault: false},
+		targetType: typeOf[T](),
+	}
+}
+
+// String Argument resolves parameter placeholders in a string.
+type stringArg struct {
+	template string
+}
+
+func (a *stringArg) Evaluate(c *Container) (interface{}, error) {
+	value, err := c.interpolate(a.template)
+	if err != nil {
+		return nil, z.Wrapf(err, "could not interpolate %q", a.template)
+	}
+
+	return value, nil
+}
+
+func (a *stringArg) convert(value interface{}, targetType reflect.Type) (interface{}, error) {
+	converted, err := convertParam(value, targetType)
+	if err != nil {
+		return nil, z.Wrapf(err, "invalid value of %q", a.template)
+	}
+
+	return converted, nil
+}
+
+func (a *stringArg) paramKeys(c *Container) (keys []string) {
+	visited := map[string]bool{}
+
+	for _, key := range placeholderKeys(a.template) {
+		keys = append(keys, c.paramKeysOf(key, visited)...)
+	}
+
+	return keys
+}
+
+func (a *stringArg) graphEdges(_ *Container, from string) (edges []GraphEdge) {
+	for _, key := range placeholderKeys(a.template) {
+		edges = append(edges, GraphEdge{From: from, To: graphParamPrefix + key, Kind: GraphEdgeParam, Label: ""})
+	}
+
+	return edges
+}
+
+func (a *stringArg) validate(c *Container) []error {
+	if _, err := a.Evaluate(c); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+// StringArg is an argument with placeholders that are resolved by the ParameterProvider of the container.
+// Placeholders are written as %db.host% or ${db.host}, with a default value as ${db.port:5432}. Parameter values
+// are inserted as they are, unless WithParamInterpolation resolves their placeholders as well. Keys may contain
+// placeholders themselves. Use %% and $${ for a literal % and ${.
+// If the template consists of a single placeholder, the parameter value keeps its type and is converted to the
+// type of the provider parameter like ParamArg values.
+func StringArg(template string) ServiceDefArg {
+	return &stringArg{template: template}
 }
 
 // Context Argument injects the context from di.Container.
@@ -97,19 +393,34 @@ func (a *contextArg) Evaluate(c *Container) (interface{}, error) {
 	return c.ctx, nil
 }
 
+func (a *contextArg) staticType(_ *Container) reflect.Type {
+	return contextType
+}
+
 func ContextArg() ServiceDefArg {
 	return &contextArg{}
 }
 
 // Container Argument injects the container from di.Container.
-type containerArg struct{}
+type containerArg struct {
+	// inBuild injects the view of the container that requests services as part of the build, see withChain
+	inBuild bool
+}
 
 func (a *containerArg) Evaluate(c *Container) (interface{}, error) {
-	return c, nil
+	if a.inBuild {
+		return c, nil
+	}
+
+	return c.unwrap(), nil
+}
+
+func (a *containerArg) staticType(_ *Container) reflect.Type {
+	return containerType
 }
 
 func ContainerArg() ServiceDefArg {
-	return &containerArg{}
+	return &containerArg{inBuild: false}
 }
 
 // EventBus Argument injects the eventBus from di.EventBus.
@@ -119,6 +430,10 @@ func (a *eventBusArg) Evaluate(c *Container) (interface{}, error) {
 	return c.eventBus, nil
 }
 
+func (a *eventBusArg) staticType(_ *Container) reflect.Type {
+	return eventBusType
+}
+
 //goland:noinspection GoUnusedExportedFunction
 func EventBusArg() ServiceDefArg {
 	return &eventBusArg{}
@@ -0,0 +1,94 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	eventbus "github.com/dtomasi/go-event-bus/v3"
+	z "github.com/dtomasi/zerrors"
+	"reflect"
+	"strings"
+)
+
+var (
+	contextType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+	containerType = reflect.TypeOf((*Container)(nil))
+	eventBusType  = reflect.TypeOf((*eventbus.EventBus)(nil))
+)
+
+// isAutowired returns true if autowiring is enabled for given definition.
+func (c *Container) isAutowired(def *ServiceDef) bool {
+	return c.autowire || def.options.autowire
+}
+
+// resolveArgs returns the args to call the provider of given definition with.
+// If autowiring is enabled, all provider parameters that are not covered by the explicitly defined args are
+// resolved by type. On error the explicitly defined args are returned.
+func (c *Container) resolveArgs(def *ServiceDef) ([]ServiceDefArg, error) {
+	if !c.isAutowired(def) || def.provider == nil {
+		return def.args, nil
+	}
+
+	providerType := reflect.TypeOf(def.provider)
+	if providerType.Kind() != reflect.Func || providerType.NumIn() <= len(def.args) {
+		return def.args, nil
+	}
+
+	args := append([]ServiceDefArg{}, def.args...)
+
+	for i := len(def.args); i < providerType.NumIn(); i++ {
+		arg, err := c.autowireArg(def, providerType.In(i))
+		if err != nil {
+			return def.args, z.Wrapf(err, "could not autowire parameter %d", i)
+		}
+
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// autowireArg returns an argument for a provider parameter of given type.
+func (c *Container) autowireArg(def *ServiceDef, paramType reflect.Type) (ServiceDefArg, error) {
+	switch paramType {
+	case contextType:
+		return ContextArg(), nil
+	case containerType:
+		return ContainerArg(), nil
+	case eventBusType:
+		return EventBusArg(), nil
+	}
+
+	// a service bound to the type is preferred over all other candidates.
+	if _, ok := c.lookupAlias(typeRef{t: paramType}); ok {
+		return ServiceArg(typeRef{t: paramType}), nil
+	}
+
+	var candidates []fmt.Stringer
+
+	for key, candidate := range c.allServiceDefs() {
+		if key == def.ref {
+			continue
+		}
+
+		if serviceType := c.serviceType(candidate); serviceType != nil && serviceType.AssignableTo(paramType) {
+			candidates = append(candidates, key)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, z.NewWithOpts(
+			fmt.Sprintf("no service found that is assignable to %s", paramType),
+			z.WithType(AutowireNoCandidateError),
+		)
+	case 1:
+		return ServiceArg(candidates[0]), nil
+	default:
+		names := strings.Join(refNames(sortRefs(candidates)), ", ")
+
+		return nil, z.NewWithOpts(
+			fmt.Sprintf("multiple services are assignable to %s: %s", paramType, names),
+			z.WithType(AutowireAmbiguousError),
+		)
+	}
+}
@@ -0,0 +1,73 @@
+package di_test
+
+import (
+	"context"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAutowire(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("TestService1")).
+			Provider(NewTestService1).
+			Args(di.ContextArg(), di.ContainerArg(), di.InterfaceArg(true), di.InterfaceArg("foo")),
+		di.NewServiceDef(di.StringRef("TestService2")).
+			Opts(di.Autowire()).
+			Provider(func(ctx context.Context, t1 *TestService1, testString string) *TestService2 {
+				return NewTestService2(t1, nil, ctx != nil, testString, "")
+			}),
+		di.NewServiceDef(di.StringRef("TestService3")).
+			Opts(di.Autowire()).
+			Provider(func(testString string, t1 *TestService1) *TestService2 {
+				return NewTestService2(t1, nil, t1.True(), testString, "")
+			}).
+			// explicit args override autowiring by position
+			Args(di.InterfaceArg("bar")),
+	)
+	container.Set(di.StringRef("string"), "baz")
+
+	assert.NoError(t, container.Build())
+
+	t2 := di.MustGet[*TestService2](container, di.StringRef("TestService2"))
+	assert.True(t, t2.True())
+	assert.Equal(t, "baz", t2.TestString())
+	assert.IsType(t, &TestService1{}, t2.TestService1()) //nolint:exhaustivestruct
+
+	t3 := di.MustGet[*TestService2](container, di.StringRef("TestService3"))
+	assert.Equal(t, "bar", t3.TestString())
+}
+
+func TestWithAutowiring(t *testing.T) {
+	container := di.NewServiceContainer(di.WithAutowiring())
+	container.Register(
+		di.NewServiceDef(di.StringRef("TestService1")).
+			Provider(func(ctx context.Context, c *di.Container) *TestService1 {
+				return NewTestService1(ctx, c, true, "foo")
+			}),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.Equal(t, container, di.MustGet[*TestService1](container, di.StringRef("TestService1")).Container())
+}
+
+func TestAutowire_Errors(t *testing.T) {
+	container := di.NewServiceContainer(di.WithAutowiring())
+	container.Register(
+		di.NewServiceDef(di.StringRef("missing")).
+			Provider(func(t2 *TestService2) *TestService1 { return nil }),
+		di.NewServiceDef(di.StringRef("ambiguous")).
+			Provider(func(s string) *TestService1 { return &TestService1{testString: s} }), //nolint:exhaustivestruct
+	)
+	container.Set(di.StringRef("foo"), "foo")
+	container.Set(di.StringRef("bar"), "bar")
+
+	_, err := container.Get(di.StringRef("missing"))
+	assertErrorType(t, err, di.AutowireNoCandidateError)
+	assert.Contains(t, err.Error(), "*di_test.TestService2")
+
+	_, err = container.Get(di.StringRef("ambiguous"))
+	assertErrorType(t, err, di.AutowireAmbiguousError)
+	assert.Contains(t, err.Error(), "bar, foo")
+}
@@ -0,0 +1,187 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"sync"
+)
+
+// buildChain is a build in progress and the chain of builds that requested it.
+// Each build evaluates its args with a view of the container holding its chain, so a service that is requested
+// while it is being built is detected instead of waiting for its own build forever. This covers dependencies
+// that are fetched inside of providers, which are not known to the static cycle detection.
+type buildChain struct {
+	parent *buildChain
+	build  *pendingBuild
+}
+
+// buildTracker links the builds in progress of a container and its scopes.
+// It guards the active, child, waitsFor and nested fields of all pending builds.
+type buildTracker struct {
+	mu sync.Mutex
+}
+
+func newBuildTracker() *buildTracker {
+	return &buildTracker{mu: sync.Mutex{}}
+}
+
+// withChain returns a view of the container whose requests are part of given build chain.
+func (c *Container) withChain(chain *buildChain) *Container {
+	view := *c
+	view.chain = chain
+	view.origin = c.unwrap()
+
+	return &view
+}
+
+// unwrap returns the container a view was created from, or the container itself if it is not a view.
+func (c *Container) unwrap() *Container {
+	if c.origin != nil {
+		return c.origin
+	}
+
+	return c
+}
+
+// innermost returns the innermost build of the chain that is still active. A view of the container may outlive
+// its build, e.g. if a provider keeps the injected container, so finished builds are skipped.
+func (b *buildTracker) innermost(chain *buildChain) *pendingBuild {
+	for ; chain != nil; chain = chain.parent {
+		if chain.build.active {
+			return chain.build
+		}
+	}
+
+	return nil
+}
+
+// inChain reports whether the build is an active build of given chain.
+func (b *buildTracker) inChain(chain *buildChain, build *pendingBuild) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ; chain != nil; chain = chain.parent {
+		if chain.build == build && build.active {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enter registers the build as part of the chain and returns the extended chain and a function to unregister it.
+// If the chain is already building the same service, the chain of refs forming the cycle is returned instead.
+func (b *buildTracker) enter(chain *buildChain, build *pendingBuild) (*buildChain, func(), []fmt.Stringer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var active []*pendingBuild
+
+	for current := chain; current != nil; current = current.parent {
+		if !current.build.active {
+			continue
+		}
+
+		active = append([]*pendingBuild{current.build}, active...)
+
+		if current.build.ref == build.ref {
+			return nil, nil, append(buildRefs(active), build.ref)
+		}
+	}
+
+	parent := b.innermost(chain)
+	if parent != nil {
+		parent.child = build
+	}
+
+	build.active = true
+
+	return &buildChain{parent: chain, build: build}, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		build.active = false
+
+		if parent != nil && parent.child == build {
+			parent.child = nil
+			parent.nested = append(parent.nested, build.def)
+		}
+	}, nil
+}
+
+// nestedCount returns the number of services that were built while the build was active so far.
+func (b *buildTracker) nestedCount(build *pendingBuild) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(build.nested)
+}
+
+// nestedSince returns the services that were built while the build was active, starting at given count.
+func (b *buildTracker) nestedSince(build *pendingBuild, count int) []*ServiceDef {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*ServiceDef{}, build.nested[count:]...)
+}
+
+// wait registers that the chain waits for the build and returns a function to unregister it.
+// If the build waits for the chain directly or through builds of other goroutines, waiting would never end.
+// In this case the chain of refs forming the cycle is returned instead.
+func (b *buildTracker) wait(chain *buildChain, build *pendingBuild) (done func(), cycle []fmt.Stringer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// requests from outside of a build cannot be waited for by any build.
+	waiter := b.innermost(chain)
+	if waiter == nil {
+		return func() {}, nil
+	}
+
+	visited := map[*pendingBuild]bool{}
+
+	for current := build; current != nil && !visited[current]; {
+		visited[current] = true
+
+		// follow the builds that were requested by the build down to the one that is currently running.
+		for ; current != nil; current = current.child {
+			cycle = append(cycle, current.ref)
+
+			if current == waiter {
+				return nil, append(cycle, build.ref)
+			}
+
+			if current.child == nil {
+				break
+			}
+		}
+
+		current = current.waitsFor
+	}
+
+	waiter.waitsFor = build
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		waiter.waitsFor = nil
+	}, nil
+}
+
+func buildRefs(builds []*pendingBuild) []fmt.Stringer {
+	refs := make([]fmt.Stringer, 0, len(builds))
+	for _, build := range builds {
+		refs = append(refs, build.ref)
+	}
+
+	return refs
+}
+
+// circularDependencyError returns the error for a cycle of service refs.
+func circularDependencyError(cycle []fmt.Stringer) error {
+	return z.NewWithOpts(
+		fmt.Sprintf("circular dependency detected: %s", formatRefChain(cycle)),
+		z.WithType(CircularDependencyError),
+	)
+}
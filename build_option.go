@@ -0,0 +1,24 @@
+package di
+
+// BuildOption defines an option function for Container.Build.
+type BuildOption func(bo *buildOptions)
+
+// buildOptions holds the options of a single container build.
+type buildOptions struct {
+	parallelism int
+}
+
+// newBuildOptions returns a buildOptions instance with defaults.
+func newBuildOptions() *buildOptions {
+	return &buildOptions{
+		parallelism: 1,
+	}
+}
+
+// WithParallelism defines how many services are built concurrently at max.
+// Services are built in dependency order, so a service is built once all services it depends on are built.
+func WithParallelism(n int) BuildOption {
+	return func(opts *buildOptions) {
+		opts.parallelism = n
+	}
+}
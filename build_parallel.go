@@ -0,0 +1,129 @@
+package di
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"sync"
+)
+
+// buildParallel builds given services with a bounded number of workers.
+// A service is scheduled as soon as all services it depends on that are part of this build are done.
+// Build errors of all services are collected and returned together.
+func (c *Container) buildParallel(refs []fmt.Stringer, parallelism int) (errs error) {
+	inBuild := make(map[fmt.Stringer]bool, len(refs))
+	for _, ref := range refs {
+		inBuild[ref] = true
+	}
+
+	// count the dependencies of each service and remember which services wait for it.
+	waitingFor := make(map[fmt.Stringer]int, len(refs))
+	dependents := map[fmt.Stringer][]fmt.Stringer{}
+
+	for _, ref := range refs {
+		for _, dep := range c.buildDependencies(ref, inBuild) {
+			waitingFor[ref]++
+			dependents[dep] = append(dependents[dep], ref)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		inFlight int
+	)
+
+	queue := make(chan fmt.Stringer, len(refs))
+
+	for _, ref := range refs {
+		if waitingFor[ref] == 0 {
+			queue <- ref
+			inFlight++
+		}
+	}
+
+	if inFlight == 0 {
+		close(queue)
+	}
+
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for ref := range queue {
+				err := c.buildRef(ref)
+
+				mu.Lock()
+				if err != nil {
+					errs = multierror.Append(errs, err)
+				}
+
+				// dependents are scheduled even if the build failed, so their errors are reported as well.
+				for _, dependent := range dependents[ref] {
+					waitingFor[dependent]--
+					if waitingFor[dependent] == 0 {
+						queue <- dependent
+						inFlight++
+					}
+				}
+
+				inFlight--
+				if inFlight == 0 {
+					close(queue)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// services that were never scheduled are part of a circular dependency.
+	// building them reports the cycle.
+	for _, ref := range refs {
+		if waitingFor[ref] > 0 {
+			if err := c.buildRef(ref); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// buildDependencies returns the services of the current build a service depends on, either directly or through
+// services that are not part of the build.
+func (c *Container) buildDependencies(ref fmt.Stringer, inBuild map[fmt.Stringer]bool) []fmt.Stringer {
+	var deps []fmt.Stringer
+
+	visited := map[fmt.Stringer]bool{ref: true}
+
+	var visit func(ref fmt.Stringer)
+	visit = func(ref fmt.Stringer) {
+		def, ok := c.loadServiceDef(ref)
+		if !ok {
+			return
+		}
+
+		for _, dep := range c.dependenciesOf(def) {
+			if visited[dep] {
+				continue
+			}
+
+			visited[dep] = true
+
+			if inBuild[dep] {
+				deps = append(deps, dep)
+
+				continue
+			}
+
+			visit(dep)
+		}
+	}
+
+	visit(ref)
+
+	return deps
+}
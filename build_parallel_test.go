@@ -0,0 +1,131 @@
+package di_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// buildTracker records the order of builds and how many providers run at the same time.
+// Providers wait at a barrier until limit providers are running, so the test does not rely on timing to reach the
+// limit. Once released, providers return right away.
+type buildTracker struct {
+	mu       sync.Mutex
+	order    []string
+	running  int
+	max      int
+	limit    int
+	release  chan struct{}
+	released bool
+	timedOut bool
+}
+
+func newBuildTracker(limit int) *buildTracker {
+	return &buildTracker{limit: limit, release: make(chan struct{})} //nolint:exhaustivestruct
+}
+
+func (bt *buildTracker) track(name string) string {
+	bt.mu.Lock()
+	bt.running++
+
+	if bt.running > bt.max {
+		bt.max = bt.running
+	}
+
+	if bt.running == bt.limit && !bt.released {
+		bt.released = true
+		close(bt.release)
+	}
+	bt.mu.Unlock()
+
+	select {
+	case <-bt.release:
+	case <-time.After(5 * time.Second):
+		bt.mu.Lock()
+		bt.timedOut = true
+		bt.mu.Unlock()
+	}
+
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	bt.running--
+	bt.order = append(bt.order, name)
+
+	return name
+}
+
+func (bt *buildTracker) indexOf(name string) int {
+	for i, n := range bt.order {
+		if n == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestContainer_Build_WithParallelism(t *testing.T) {
+	bt := newBuildTracker(3)
+	container := di.NewServiceContainer()
+
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("leaf-%d", i)
+		container.Register(di.NewServiceDef(di.StringRef(name)).Provider(func() string { return bt.track(name) }))
+	}
+
+	container.Register(
+		// depends on a leaf through a lazy service that is not part of the build
+		di.NewServiceDef(di.StringRef("lazy")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(func(string) string { return bt.track("lazy") }).
+			Args(di.ServiceArg(di.StringRef("leaf-0"))),
+		di.NewServiceDef(di.StringRef("root")).
+			Provider(func(_, _, _ string) string { return bt.track("root") }).
+			Args(
+				di.ServiceArg(di.StringRef("lazy")),
+				di.ServiceArg(di.StringRef("leaf-1")),
+				di.ServiceArg(di.StringRef("leaf-2")),
+			),
+	)
+
+	assert.NoError(t, container.Build(di.WithParallelism(3)))
+	assert.Len(t, bt.order, 8)
+	// the barrier was released, so 3 providers ran at the same time, but never more.
+	assert.False(t, bt.timedOut, "less than 3 providers ran at the same time")
+	assert.Equal(t, 3, bt.max)
+
+	for _, dep := range []string{"lazy", "leaf-0", "leaf-1", "leaf-2"} {
+		assert.Less(t, bt.indexOf(dep), bt.indexOf("root"), dep)
+	}
+}
+
+func TestContainer_Build_WithParallelism_Errors(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("failing")).
+			Provider(func() (string, error) { return "", errors.New("failed") }), //nolint:goerr113
+		di.NewServiceDef(di.StringRef("dependent")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("failing"))),
+		di.NewServiceDef(di.StringRef("A")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("B"))),
+		di.NewServiceDef(di.StringRef("B")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("A"))),
+		di.NewServiceDef(di.StringRef("ok")).
+			Provider(func() string { return "ok" }),
+	)
+
+	err := container.Build(di.WithParallelism(4))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "4 errors occurred")
+	assert.Contains(t, err.Error(), "A -> B -> A")
+	assert.Contains(t, err.Error(), "error while building service dependent")
+	assert.Equal(t, "ok", di.MustGet[string](container, di.StringRef("ok")))
+}
@@ -0,0 +1,193 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"strings"
+	"sync"
+)
+
+// CompositeParameterProvider is a ParameterProvider that chains several named layers of ParameterProviders.
+// Get returns the value of the first layer that has the key, so layers added first take precedence.
+// Set writes to a single layer, which is the first layer unless another one is chosen with Writable.
+// Watch reports the changes of all layers implementing WatchableParameterProvider.
+type CompositeParameterProvider struct {
+	mu       sync.RWMutex
+	layers   []parameterLayer
+	writable string
+}
+
+type parameterLayer struct {
+	name     string
+	provider ParameterProvider
+}
+
+// ParameterSource describes a layer that has a value for a key.
+type ParameterSource struct {
+	Layer string
+	Value interface{}
+}
+
+// NewCompositeParameterProvider returns a new CompositeParameterProvider instance without layers.
+func NewCompositeParameterProvider() *CompositeParameterProvider {
+	return &CompositeParameterProvider{ //nolint:exhaustivestruct
+		layers: []parameterLayer{},
+	}
+}
+
+// Layer adds a named layer with a lower precedence than all layers added before.
+func (p *CompositeParameterProvider) Layer(name string, provider ParameterProvider) *CompositeParameterProvider {
+	p.mu.Lock()
+	p.layers = append(p.layers, parameterLayer{name: name, provider: provider})
+	p.mu.Unlock()
+
+	return p
+}
+
+// Writable sets the name of the layer Set writes to.
+func (p *CompositeParameterProvider) Writable(name string) *CompositeParameterProvider {
+	p.mu.Lock()
+	p.writable = name
+	p.mu.Unlock()
+
+	return p
+}
+
+// Get returns the value of the first layer that has the key.
+// If no layer has the key, a ParamNotFoundError is returned that lists the errors of all layers.
+func (p *CompositeParameterProvider) Get(key string) (interface{}, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	reasons := make([]string, 0, len(p.layers))
+
+	for _, layer := range p.layers {
+		value, err := layer.provider.Get(key)
+		if err == nil {
+			return value, nil
+		}
+
+		reasons = append(reasons, fmt.Sprintf("layer %s: %s", layer.name, err))
+	}
+
+	return nil, z.NewWithOpts(
+		fmt.Sprintf("parameter %s not found in any layer [%s]", key, strings.Join(reasons, "; ")),
+		z.WithType(ParamNotFoundError),
+	)
+}
+
+// Set sets the value in the writable layer.
+func (p *CompositeParameterProvider) Set(key string, value interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, layer := range p.layers {
+		if p.writable == "" || layer.name == p.writable {
+			return layer.provider.Set(key, value)
+		}
+	}
+
+	if p.writable == "" {
+		return z.NewWithOpts("no layer defined", z.WithType(ParamProviderNotDefinedError))
+	}
+
+	return z.NewWithOpts(
+		fmt.Sprintf("writable layer %s not defined", p.writable),
+		z.WithType(ParamProviderNotDefinedError),
+	)
+}
+
+// Sources returns all layers that have a value for the key in order of precedence.
+// The first source is the one Get returns the value of. It is meant for debugging overrides.
+func (p *CompositeParameterProvider) Sources(key string) []ParameterSource {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var sources []ParameterSource
+
+	for _, layer := range p.layers {
+		if value, err := layer.provider.Get(key); err == nil {
+			sources = append(sources, ParameterSource{Layer: layer.name, Value: value})
+		}
+	}
+
+	return sources
+}
+
+// Watch forwards the changes of all layers implementing WatchableParameterProvider until ctx is done.
+// Changes of keys that a layer with a higher precedence has a value for are skipped, as they do not change the
+// value Get returns. Layers added afterwards are not watched. The channel is closed once all watched layers closed
+// their channels or ctx is done, or right away if no layer is watchable.
+func (p *CompositeParameterProvider) Watch(ctx context.Context) <-chan ParamChange {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	out := make(chan ParamChange)
+	wg := sync.WaitGroup{}
+
+	for i, layer := range p.layers {
+		watchable, ok := layer.provider.(WatchableParameterProvider)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(index int, changes <-chan ParamChange) {
+			defer wg.Done()
+
+			p.forwardChanges(ctx, index, changes, out)
+		}(i, watchable.Watch(ctx))
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// forwardChanges sends the changes of the layer at given index to out unless they are shadowed.
+func (p *CompositeParameterProvider) forwardChanges(
+	ctx context.Context,
+	index int,
+	changes <-chan ParamChange,
+	out chan<- ParamChange,
+) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+
+			if p.shadowed(index, change.Key) {
+				continue
+			}
+
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// shadowed reports whether a layer with a higher precedence than the layer at given index has the key.
+func (p *CompositeParameterProvider) shadowed(index int, key string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, layer := range p.layers[:index] {
+		if _, err := layer.provider.Get(key); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package di_test
+
+import (
+	"context"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newCompositeParameterProvider(t *testing.T) *di.CompositeParameterProvider {
+	t.Helper()
+
+	t.Setenv("DB_HOST", "db.example.com")
+
+	file, err := di.NewFileParameterProvider(writeParameterFile(t, "parameters.yaml", parametersYAML))
+	assert.NoError(t, err)
+
+	return di.NewCompositeParameterProvider().
+		Layer("env", di.NewEnvParameterProvider()).
+		Layer("file", file)
+}
+
+func TestCompositeParameterProvider_Get(t *testing.T) {
+	pp := newCompositeParameterProvider(t)
+
+	v, err := pp.Get("db.host")
+	assert.NoError(t, err)
+	assert.Equal(t, "db.example.com", v)
+
+	v, err = pp.Get("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, v)
+
+	v, err = pp.Get("db.user")
+	assert.Nil(t, v)
+	assertErrorType(t, err, di.ParamNotFoundError)
+	assert.Contains(t, err.Error(), "layer env")
+	assert.Contains(t, err.Error(), "layer file")
+}
+
+func TestCompositeParameterProvider_Set(t *testing.T) {
+	pp := newCompositeParameterProvider(t)
+
+	// writes to the first layer by default
+	assert.NoError(t, pp.Set("db.port", 5433))
+
+	v, err := pp.Get("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5433, v)
+
+	pp.Writable("file")
+	assert.NoError(t, pp.Set("db.user", "admin"))
+	assert.Equal(t, []di.ParameterSource{{Layer: "file", Value: "admin"}}, pp.Sources("db.user"))
+
+	pp.Writable("missing")
+	assertErrorType(t, pp.Set("db.user", "root"), di.ParamProviderNotDefinedError)
+
+	assertErrorType(t, di.NewCompositeParameterProvider().Set("db.user", "root"), di.ParamProviderNotDefinedError)
+}
+
+func TestCompositeParameterProvider_Sources(t *testing.T) {
+	pp := newCompositeParameterProvider(t)
+
+	assert.Equal(t, []di.ParameterSource{
+		{Layer: "env", Value: "db.example.com"},
+		{Layer: "file", Value: "localhost"},
+	}, pp.Sources("db.host"))
+	assert.Empty(t, pp.Sources("db.user"))
+}
+
+func TestCompositeParameterProvider_Watch(t *testing.T) {
+	overrides := newWatchableParameterProvider(map[string]interface{}{"db.host": "override"})
+	defaults := newWatchableParameterProvider(map[string]interface{}{"db.host": "localhost", "db.port": 5432})
+
+	pp := di.NewCompositeParameterProvider().
+		Layer("overrides", overrides).
+		Layer("env", di.NewEnvParameterProvider()).
+		Layer("defaults", defaults)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := pp.Watch(ctx)
+
+	// db.host of defaults is shadowed by overrides
+	defaults.Change("db.host", "db.example.com")
+	defaults.Change("db.port", 5433)
+	overrides.Change("db.host", "other")
+
+	received := map[string]interface{}{}
+
+	for len(received) < 2 {
+		select {
+		case change := <-changes:
+			received[change.Key] = change.NewValue
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for parameter changes")
+		}
+	}
+
+	assert.Equal(t, map[string]interface{}{"db.port": 5433, "db.host": "other"}, received)
+
+	// the channel is closed once ctx is done
+	cancel()
+
+	for range changes {
+		t.Fatal("unexpected parameter change")
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/dtomasi/go-event-bus/v3"
 	z "github.com/dtomasi/zerrors"
 	"github.com/go-logr/logr"
+	"github.com/hashicorp/go-multierror"
 	"reflect"
+	"time"
 )
 
 // Container is the actual service container struct.
@@ -25,11 +27,41 @@ type Container struct {
 	// eventBus is the eventbus instance
 	eventBus *eventbus.EventBus
 
+	// events publishes events to the eventBus in order
+	events *eventPublisher
+
+	// builds tracks the builds in progress to detect services requesting themselves
+	builds *buildTracker
+
+	// lifecycle records whether Build started the services
+	lifecycle *containerLifecycle
+
+	// chain is the build this view of the container evaluates args for, see withChain
+	chain *buildChain
+
+	// origin is the container this is a view of, see withChain
+	origin *Container
+
 	// The ParameterProvider
 	paramProvider ParameterProvider
 
 	// Map of Service definitions
 	serviceDefs *ServiceDefMap
+
+	// aliases maps alternative refs and bound types to service refs
+	aliases *aliasMap
+
+	// decorators wrap service instances after construction
+	decorators *decoratorMap
+
+	// autowire enables autowiring for all service definitions
+	autowire bool
+
+	// interpolateParams enables placeholders in parameter values
+	interpolateParams bool
+
+	// parent is the container a scope was created from
+	parent *Container
 }
 
 // NewServiceContainer returns a new Container instance.
@@ -38,14 +70,20 @@ func NewServiceContainer(opts ...Option) *Container {
 		ctx:           context.Background(),
 		logger:        fakr.New(),
 		eventBus:      eventbus.NewEventBus(),
+		builds:        newBuildTracker(),
+		lifecycle:     newContainerLifecycle(),
 		paramProvider: &NoParameterProvider{},
 		serviceDefs:   NewServiceDefMap(),
+		aliases:       newAliasMap(),
+		decorators:    newDecoratorMap(),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	c.events = newEventPublisher(c.eventBus)
+
 	// Get the context cancel function
 	c.ctx, c.ctxCancelFun = context.WithCancel(c.ctx)
 
@@ -70,6 +108,12 @@ func (c *Container) GetEventBus() *eventbus.EventBus {
 	return c.eventBus
 }
 
+// ServiceDefs returns the map of service definitions that are registered to the container.
+// Definitions inherited from a parent container are not included.
+func (c *Container) ServiceDefs() *ServiceDefMap {
+	return c.serviceDefs
+}
+
 // GetContext returns the context.
 func (c *Container) GetContext() context.Context {
 	return c.ctx
@@ -90,6 +134,7 @@ func (c *Container) Register(defs ...*ServiceDef) {
 // Set sets a service to container.
 func (c *Container) Set(ref fmt.Stringer, s interface{}) *Container {
 	c.serviceDefs.Store(ref, &ServiceDef{ //nolint:exhaustivestruct
+		ref:      ref,
 		instance: s,
 		options:  newServiceOptions(),
 		tags:     []fmt.Stringer{},
@@ -101,25 +146,122 @@ func (c *Container) Set(ref fmt.Stringer, s interface{}) *Container {
 }
 
 // Get returns a requested service.
+// It is safe to call Get concurrently. A service is built only once, even if it is requested concurrently.
+// All concurrent requests receive the same instance or error.
 func (c *Container) Get(ref fmt.Stringer) (interface{}, error) {
-	// s is a service object
-	if sd, ok := c.serviceDefs.Load(ref); ok {
-		if sd.instance == nil || sd.options.alwaysRebuild {
-			var err error
-			sd.instance, err = c.buildServiceInstance(sd)
+	c.events.publishAsync(EventTopicServiceRequested, ServiceRequestedEvent{Ref: ref})
 
-			if err != nil {
-				return nil, err
-			}
+	return c.get(ref)
+}
+
+// get returns a requested service like Get, but without publishing EventTopicServiceRequested.
+// It is used for requests of the container itself, e.g. to build services or to evaluate args.
+func (c *Container) get(ref fmt.Stringer) (interface{}, error) {
+	target, err := c.resolveAlias(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.loadServiceDef(target); !ok && target != ref {
+		return nil, aliasTargetNotFound(ref, target)
+	}
+
+	sd, owner, err := c.resolveServiceDef(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if sd.options.alwaysRebuild {
+		return owner.buildService(sd, newPendingBuild(sd), c.chain)
+	}
+
+	return owner.getOrBuildInstance(sd, c.chain)
+}
+
+// getOrBuildInstance returns the instance of given definition and builds it if required.
+// The chain is the build that requested the service, or nil if it was requested from outside of a build.
+func (c *Container) getOrBuildInstance(sd *ServiceDef, chain *buildChain) (instance interface{}, err error) {
+	sd.mu.Lock()
+
+	if sd.instance != nil {
+		instance = sd.instance
+		sd.mu.Unlock()
+
+		return instance, nil
+	}
+
+	// wait for a build that is already in progress
+	if build := sd.pending; build != nil {
+		constructed := build.constructed
+		sd.mu.Unlock()
+
+		// the instance is constructed and its calls are executed, which may request the service again.
+		if constructed != nil && c.builds.inChain(chain, build) {
+			return constructed, nil
+		}
+
+		// a build that waits for the requesting build would never finish.
+		done, cycle := c.builds.wait(chain, build)
+		if cycle != nil {
+			return nil, circularDependencyError(cycle)
 		}
 
-		return sd.instance, nil
+		<-build.done
+		done()
+
+		return build.instance, build.err
 	}
 
-	return nil, z.NewWithOpts(
-		fmt.Sprintf("services %s not found", ref),
-		z.WithType(ServiceNotFoundError),
-	)
+	build := newPendingBuild(sd)
+	sd.pending = build
+	sd.mu.Unlock()
+
+	defer func() {
+		// requests waiting for the build receive an error if the provider panicked.
+		r := recover()
+		if r != nil {
+			build.instance, build.err = nil, z.NewWithOpts(
+				fmt.Sprintf("panic while building service %s: %v", sd.ref, r),
+				z.WithType(ServiceBuildError),
+			)
+		}
+
+		sd.mu.Lock()
+		if sd.pending == build {
+			sd.pending = nil
+		}
+		sd.mu.Unlock()
+
+		close(build.done)
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	build.instance, build.err = c.buildService(sd, build, chain)
+
+	if build.err == nil {
+		sd.setState(serviceBuilt)
+
+		// services that are built after Build started the services are started right away.
+		if c.servicesStarted() {
+			build.err = c.startService(sd, build.instance)
+		}
+	}
+
+	sd.mu.Lock()
+	if build.err == nil {
+		sd.instance = build.instance
+	}
+	sd.pending = nil
+	sd.mu.Unlock()
+
+	if build.err != nil {
+		build.instance = nil
+	}
+
+	return build.instance, build.err
 }
 
 // MustGet returns a service instance or panics on error.
@@ -134,9 +276,70 @@ func (c *Container) MustGet(ref fmt.Stringer) interface{} {
 
 // FindByTags finds all service instances with given tags and returns them as a slice.
 func (c *Container) FindByTags(tags []fmt.Stringer) ([]interface{}, error) {
-	var instances []interface{}
+	var (
+		instances []interface{}
+		errs      error
+	)
+
+	for _, ref := range c.findRefsByTags(tags) {
+		// use get to ensure the service is built if not already.
+		s, err := c.get(ref)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+
+			continue
+		}
+
+		instances = append(instances, s)
+	}
+
+	if errs != nil {
+		return nil, findByTagsError(tags, errs)
+	}
+
+	return instances, nil
+}
 
-	err := c.serviceDefs.Range(func(key fmt.Stringer, def *ServiceDef) error {
+// findByTagsError wraps the errors collected while finding services by tags. The error has the type of the
+// collected errors if all of them share it, otherwise it is a ServiceBuildError.
+func findByTagsError(tags []fmt.Stringer, errs error) error {
+	errType := ServiceBuildError
+
+	if merr, ok := errs.(*multierror.Error); ok && len(merr.Errors) > 0 {
+		errType = errorTypeOf(merr.Errors[0])
+
+		for _, err := range merr.Errors[1:] {
+			if errorTypeOf(err) != errType {
+				errType = ServiceBuildError
+
+				break
+			}
+		}
+	}
+
+	return z.WrapWithOpts(
+		errs,
+		fmt.Sprintf("could not get services tagged %s", refNames(tags)),
+		z.WithType(errType),
+	)
+}
+
+// errorTypeOf returns the ErrorType of err, or ServiceBuildError if it has none.
+func errorTypeOf(err error) ErrorType {
+	if typed, ok := err.(z.TypeAwareError); ok {
+		if errType, ok := typed.Type().(ErrorType); ok {
+			return errType
+		}
+	}
+
+	return ServiceBuildError
+}
+
+// findRefsByTags returns the refs of all services that have all given tags, sorted by name.
+func (c *Container) findRefsByTags(tags []fmt.Stringer) []fmt.Stringer {
+	var refs []fmt.Stringer
+
+	for key, def := range c.allServiceDefs() {
 		matchCount := 0
 		for _, searchTag := range tags {
 			if containsTag(def.tags, searchTag) {
@@ -145,71 +348,204 @@ func (c *Container) FindByTags(tags []fmt.Stringer) ([]interface{}, error) {
 		}
 
 		if matchCount == len(tags) {
-			// use Get to ensure the service is built if not already.
-			s, getErr := c.Get(key)
-			if getErr != nil {
-				return getErr
-			}
-			instances = append(instances, s)
+			refs = append(refs, key)
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
 	}
 
-	return instances, nil
+	return sortRefs(refs)
 }
 
 // Build will build the service container.
-func (c *Container) Build() (err error) {
+// By default services are built one by one. Use WithParallelism to build independent services concurrently.
+// Once all services are built, services implementing Starter are started in dependency order. If a service fails
+// to start, the services started so far are stopped again and the error is returned.
+func (c *Container) Build(opts ...BuildOption) (err error) {
 	defer z.WrapPtrWithOpts(&err, "error while building container", z.WithType(ContainerBuildError))
 
-	c.logger.V(utils.LogLevelDebug).Info("starting container build")
+	options := newBuildOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	c.logger.V(utils.LogLevelDebug).Info("starting container build", "parallelism", options.parallelism)
 
-	err = c.serviceDefs.Range(func(key fmt.Stringer, serviceDef *ServiceDef) error {
-		c.logger.V(utils.LogLevelDebug).Info("building services", "name", key.String())
+	var refs []fmt.Stringer
 
-		// skip lazy initializing services here
-		if serviceDef.options.buildOnFirstRequest || serviceDef.options.alwaysRebuild {
-			c.logger.V(utils.LogLevelDebug).Info("skipping service because its set to lazy or should be rebuilt on each request",
-				"name", key.String())
+	_ = c.serviceDefs.Range(func(key fmt.Stringer, serviceDef *ServiceDef) error {
+		// skip lazy initializing and scoped services here
+		if serviceDef.options.buildOnFirstRequest || serviceDef.options.alwaysRebuild || serviceDef.options.scoped {
+			c.logger.V(utils.LogLevelDebug).Info(
+				"skipping service because its set to lazy, scoped or should be rebuilt on each request",
+				"name", key.String(),
+			)
 
 			return nil
 		}
 
-		// do not rebuild existing service instances
-		if !serviceDef.options.alwaysRebuild && serviceDef.instance != nil {
-			return nil
+		refs = append(refs, key)
+
+		return nil
+	})
+
+	if options.parallelism > 1 {
+		err = c.buildParallel(sortRefs(refs), options.parallelism)
+	} else {
+		err = c.buildSequential(sortRefs(refs))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// services that are built while starting are started right away.
+	c.lifecycle.setStarted(true)
+
+	if err = c.startServices(); err != nil {
+		c.lifecycle.setStarted(false)
+
+		return err
+	}
+
+	c.logger.V(utils.LogLevelDebug).Info("container built successfully")
+	c.events.publish(EventTopicDIReady, c)
+
+	return nil
+}
+
+// buildSequential builds given services one by one and collects all build errors.
+func (c *Container) buildSequential(refs []fmt.Stringer) (errs error) {
+	for _, ref := range refs {
+		if err := c.buildRef(ref); err != nil {
+			errs = multierror.Append(errs, err)
 		}
+	}
+
+	return errs
+}
+
+// buildRef builds a single service if it is not built yet.
+func (c *Container) buildRef(ref fmt.Stringer) error {
+	c.logger.V(utils.LogLevelDebug).Info("building services", "name", ref.String())
+
+	// we just run get without expecting an instance is returned.
+	// this will trigger build if definition instance is nil and does not rebuild existing instances.
+	_, err := c.get(ref)
 
-		// we just run get without expecting an instance is returned.
-		// this will trigger build if definition instance is nil.
-		_, getErr := c.Get(key)
-		if getErr != nil {
-			return getErr
+	return err
+}
+
+// Close tears down all built services in reverse dependency order and cancels the container context afterwards.
+// Each service is disposed by the Disposer of its ServiceDef or, if not defined, by calling Close(ctx) error or
+// io.Closer on the instance. Services implementing Stopper are stopped before. Instances passed via Set
+// are owned by the caller, and instances of services that are rebuilt on each request are owned by the requester,
+// so both are left untouched.
+// All disposal errors are collected and returned together.
+func (c *Container) Close(ctx context.Context) (err error) {
+	c.logger.V(utils.LogLevelDebug).Info("closing container")
+	c.events.publish(EventTopicContainerClosing, ContainerClosingEvent{Container: c})
+
+	defer func() {
+		c.events.publish(EventTopicContainerClosed, ContainerClosedEvent{Container: c, Err: err})
+	}()
+
+	defer z.WrapPtrWithOpts(&err, "error while closing container", z.WithType(ContainerCloseError))
+
+	c.lifecycle.setStarted(false)
+
+	var built []fmt.Stringer
+
+	_ = c.serviceDefs.Range(func(key fmt.Stringer, def *ServiceDef) error {
+		if def.provider != nil && !def.options.alwaysRebuild && def.getInstance() != nil {
+			built = append(built, key)
 		}
 
-		// return true as we want to get all build errors as an output here.
 		return nil
 	})
 
+	ordered := c.sortByDependencies(built)
+
+	// dependents have to be stopped and disposed before the services they depend on.
+	for i := len(ordered) - 1; i >= 0; i-- {
+		def, ok := c.serviceDefs.Load(ordered[i])
+		if !ok {
+			continue
+		}
+
+		if stopErr := c.stopService(ctx, def); stopErr != nil {
+			err = multierror.Append(err, stopErr)
+		}
+
+		if disposeErr := c.disposeService(ctx, def); disposeErr != nil {
+			err = multierror.Append(err, disposeErr)
+		}
+	}
+
+	c.CancelContext()
+
 	if err != nil {
 		return err
 	}
 
-	c.logger.V(utils.LogLevelDebug).Info("container built successfully")
-	c.eventBus.Publish(EventTopicDIReady.String(), c)
+	c.logger.V(utils.LogLevelDebug).Info("container closed successfully")
 
 	return nil
 }
 
+func (c *Container) disposeService(ctx context.Context, def *ServiceDef) (err error) {
+	defer z.WrapPtrWithOpts(&err,
+		fmt.Sprintf("error while disposing service %s", def.ref),
+		z.WithType(ServiceDisposeError),
+	)
+
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	c.logger.V(utils.LogLevelDebug).Info("disposing service", "name", def.ref.String())
+
+	return disposeInstance(ctx, def, def.takeInstance())
+}
+
+// callReflectValueWithArgs calls the callable with the evaluated args and returns the value it returned.
+// The callable must return a value, optionally followed by an error.
 func (c *Container) callReflectValueWithArgs(
 	callable reflect.Value,
 	serviceDefArgs []ServiceDefArg,
 ) (interface{}, error) {
+	returnValues, err := c.callReflectValue(callable, serviceDefArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(returnValues) {
+	case 0:
+		return nil, z.NewWithOpts(
+			"callable must return a value (interface{}, error). Got 0 return values",
+			z.WithType(CallableToManyReturnValuesError),
+		)
+	case 1:
+		return returnValues[0].Interface(), nil
+	case 2: // nolint:gomnd
+		providerErr, ok := returnValues[1].Interface().(error)
+		if !ok {
+			providerErr = nil
+		}
+
+		return returnValues[0].Interface(), providerErr
+
+	default:
+		return nil,
+			z.NewWithOpts(
+				fmt.Sprintf("callable can only have 2 return values at max (interface{}, error). Got %d",
+					len(returnValues),
+				),
+				z.WithType(CallableToManyReturnValuesError),
+			)
+	}
+}
+
+// callReflectValue calls the callable with the evaluated args and returns all values it returned.
+func (c *Container) callReflectValue(callable reflect.Value, serviceDefArgs []ServiceDefArg) ([]reflect.Value, error) {
 	if callable.Type().Kind() != reflect.Func {
 		return nil, z.NewWithOpts("callable not a function", z.WithType(CallableNotAFuncError))
 	}
@@ -233,14 +569,17 @@ func (c *Container) callReflectValueWithArgs(
 	for i := 0; i < callableNumInArgs; i++ {
 		callableInType := callable.Type().In(i)
 
+		if conv, ok := serviceDefArgs[i].(convertingArg); ok {
+			if evaluatedArgs[i], err = conv.convert(evaluatedArgs[i], callableInType); err != nil {
+				return nil, err
+			}
+		}
+
 		inArgType := reflect.TypeOf(evaluatedArgs[i])
 
+		// nil is passed as the zero value of the parameter type, e.g. a nil pointer or an empty string.
 		if inArgType == nil {
-			if callableInType.Kind() == reflect.Ptr {
-				callableInValues = append(callableInValues, reflect.New(callableInType))
-			} else {
-				callableInValues = append(callableInValues, reflect.New(callableInType).Elem())
-			}
+			callableInValues = append(callableInValues, reflect.Zero(callableInType))
 
 			continue
 		}
@@ -248,7 +587,8 @@ func (c *Container) callReflectValueWithArgs(
 		inTypeString := utils.GetType(callableInType)
 		inArgTypeString := utils.GetType(inArgType)
 
-		if inTypeString != inArgTypeString && !inArgType.Implements(callableInType) {
+		// AssignableTo covers interface parameters, Implements panics for other parameter types.
+		if inTypeString != inArgTypeString && !inArgType.AssignableTo(callableInType) {
 			return nil,
 				z.NewWithOpts(fmt.Sprintf("expected %s got %s",
 					inTypeString,
@@ -260,41 +600,115 @@ func (c *Container) callReflectValueWithArgs(
 	}
 
 	// Call the callable
-	returnValues := callable.Call(callableInValues)
-
-	switch len(returnValues) {
-	case 1:
-		return returnValues[0].Interface(), nil
-	case 2: // nolint:gomnd
-		providerErr, ok := returnValues[1].Interface().(error)
-		if !ok {
-			providerErr = nil
-		}
-
-		return returnValues[0].Interface(), providerErr
-
-	default:
-		return nil,
-			z.NewWithOpts(
-				fmt.Sprintf("callable can only have 2 return values at max (interface{}, error). Got %d",
-					len(returnValues),
-				),
-				z.WithType(CallableToManyReturnValuesError),
-			)
-	}
+	return callable.Call(callableInValues), nil
 }
 
-func (c *Container) buildServiceInstance(def *ServiceDef) (instance interface{}, err error) {
+// buildService builds a new instance of the service as part of the chain of builds that requested it.
+// The constructed instance is made available to the pending build before the calls of the definition are
+// executed.
+func (c *Container) buildService(
+	def *ServiceDef,
+	build *pendingBuild,
+	chain *buildChain,
+) (instance interface{}, err error) {
+	defer c.publishBuildEvents(def.ref)(&instance, &err)
+
 	defer z.WrapPtrWithOpts(&err,
 		fmt.Sprintf("error while building service %s", def.ref),
 		z.WithType(ServiceBuildError),
 	)
 
+	if cycle := c.findCycle(def.ref); cycle != nil {
+		return nil, circularDependencyError(cycle)
+	}
+
+	chain, leave, cycle := c.builds.enter(chain, build)
+	if cycle != nil {
+		return nil, circularDependencyError(cycle)
+	}
+
+	defer leave()
+
+	// args, decorators and calls request their services as part of this build.
+	c = c.withChain(chain)
+
+	// calls of other services that waited for this service are executed before the instance is published.
+	defer func() {
+		if err = c.runDeferredCalls(def, build, err); err != nil {
+			instance = nil
+		}
+	}()
+
 	if def.provider == nil {
 		return nil, z.NewWithOpts("provider missing", z.WithType(ProviderMissingError))
 	}
 
-	return c.callReflectValueWithArgs(reflect.ValueOf(def.provider), def.args)
+	args, err := c.resolveArgs(def)
+	if err != nil {
+		return nil, err
+	}
+
+	constructed, err := c.callReflectValueWithArgs(reflect.ValueOf(def.provider), args)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err = c.decorate(def, constructed)
+	if err != nil {
+		return nil, err
+	}
+
+	def.mu.Lock()
+	build.constructed = instance
+	def.mu.Unlock()
+
+	// services built for the calls may hold the constructed instance, so they are dropped if the build fails.
+	mark := c.builds.nestedCount(build)
+
+	defer func() {
+		if err != nil {
+			for _, nested := range c.builds.nestedSince(build, mark) {
+				nested.takeInstance()
+			}
+		}
+	}()
+
+	// calls are executed on the instance returned by the provider, decorators only wrap it.
+	if err = c.applyCalls(def, constructed, def.calls); err != nil {
+		return nil, err
+	}
+
+	if err = c.initInstance(instance); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// publishBuildEvents publishes EventTopicServiceBuilding and returns a function that publishes
+// EventTopicServiceBuilt or EventTopicServiceBuildFailed depending on the build result.
+func (c *Container) publishBuildEvents(ref fmt.Stringer) func(instance *interface{}, err *error) {
+	start := time.Now()
+
+	c.events.publishAsync(EventTopicServiceBuilding, ServiceBuildingEvent{Ref: ref})
+
+	return func(instance *interface{}, err *error) {
+		if *err != nil {
+			c.events.publishAsync(EventTopicServiceBuildFailed, ServiceBuildFailedEvent{
+				Ref:      ref,
+				Duration: time.Since(start),
+				Err:      *err,
+			})
+
+			return
+		}
+
+		c.events.publishAsync(EventTopicServiceBuilt, ServiceBuiltEvent{
+			Ref:      ref,
+			Duration: time.Since(start),
+			Instance: *instance,
+		})
+	}
 }
 
 // evaluateArgs parses the arguments and assigns values by arg type.
@@ -316,8 +730,10 @@ func (c *Container) evaluateArgs(args []ServiceDefArg) (eArgs []interface{}, err
 }
 
 func containsTag(a []fmt.Stringer, x fmt.Stringer) bool {
+	_, byName := x.(nameRef)
+
 	for _, n := range a {
-		if x == n {
+		if x == n || (byName && x.String() == n.String()) {
 			return true
 		}
 	}
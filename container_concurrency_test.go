@@ -0,0 +1,249 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const concurrentRequests = 50
+
+func runConcurrently(n int, f func(i int)) {
+	var wg sync.WaitGroup
+
+	start := make(chan struct{})
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			f(i)
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+}
+
+func TestContainer_Get_SingleFlight(t *testing.T) {
+	var calls int32
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("lazy")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(func() *TestService1 {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+
+				return &TestService1{} //nolint:exhaustivestruct
+			}),
+	)
+
+	instances := make([]interface{}, concurrentRequests)
+
+	runConcurrently(concurrentRequests, func(i int) {
+		instance, err := container.Get(di.StringRef("lazy"))
+		assert.NoError(t, err)
+
+		instances[i] = instance
+	})
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	for _, instance := range instances {
+		assert.Same(t, instances[0], instance)
+	}
+}
+
+func TestContainer_Get_SingleFlight_Error(t *testing.T) {
+	var calls int32
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("failing")).
+			Provider(func() (*TestService1, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+
+				return nil, errors.New("failed") //nolint:goerr113
+			}),
+	)
+
+	errs := make([]error, concurrentRequests)
+
+	runConcurrently(concurrentRequests, func(i int) {
+		_, errs[i] = container.Get(di.StringRef("failing"))
+	})
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	for _, err := range errs {
+		assert.Error(t, err)
+		assert.Equal(t, errs[0], err)
+	}
+
+	// a failed build is retried on the next request
+	_, err := container.Get(di.StringRef("failing"))
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestContainer_Concurrent(t *testing.T) {
+	container := di.NewServiceContainer(di.WithParameterProvider(&ParameterProviderMock{}))
+	container.Register(
+		di.NewServiceDef(di.StringRef("TestService1")).
+			Provider(NewTestService1).
+			Args(di.ContextArg(), di.ContainerArg(), di.InterfaceArg(true), di.ParamArg("foo")).
+			Tags(di.StringRef("foo")),
+		di.NewServiceDef(di.StringRef("TestService2")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(NewTestService2).
+			Args(
+				di.ServiceArg(di.StringRef("TestService1")),
+				di.ServicesByTagsArg([]fmt.Stringer{di.StringRef("foo")}),
+				di.InterfaceArg(true),
+				di.ParamArg("foo"),
+				di.InterfaceArg(""),
+			),
+	)
+
+	runConcurrently(concurrentRequests, func(i int) {
+		switch i % 4 {
+		case 0:
+			_, err := container.Get(di.StringRef("TestService2"))
+			assert.NoError(t, err)
+		case 1:
+			_, err := container.FindByTags([]fmt.Stringer{di.StringRef("foo")})
+			assert.NoError(t, err)
+		case 2:
+			container.Set(di.StringRef(fmt.Sprintf("set-%d", i)), &TestService1{}) //nolint:exhaustivestruct
+		case 3:
+			assert.NoError(t, container.Build())
+		}
+	})
+
+	assert.Equal(t,
+		di.MustGet[*TestService1](container, di.StringRef("TestService1")),
+		di.MustGet[*TestService2](container, di.StringRef("TestService2")).TestService1(),
+	)
+}
+
+// withinTimeout fails the test if f does not return in time, e.g. because of a deadlock.
+func withinTimeout(t *testing.T, f func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out, the container is deadlocked")
+	}
+}
+
+type selfRequesting struct{}
+
+func TestContainer_Get_SelfRequest(t *testing.T) {
+	for _, opts := range [][]di.ServiceOption{nil, {di.BuildAlwaysRebuild()}} {
+		container := di.NewServiceContainer()
+		container.Register(
+			di.Provide[*selfRequesting](di.StringRef("self"), func(_ context.Context, c *di.Container) (*selfRequesting, error) {
+				_, err := c.Get(di.StringRef("self"))
+
+				return &selfRequesting{}, err
+			}).Opts(opts...),
+		)
+
+		withinTimeout(t, func() {
+			_, err := container.Get(di.StringRef("self"))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "circular dependency detected: self -> self")
+		})
+	}
+}
+
+func newProvideCycleContainer() *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.Provide[string](di.StringRef("a"), func(_ context.Context, c *di.Container) (string, error) {
+			return di.Get[string](c, di.StringRef("b"))
+		}),
+		di.Provide[string](di.StringRef("b"), func(_ context.Context, c *di.Container) (string, error) {
+			return di.Get[string](c, di.StringRef("a"))
+		}),
+	)
+
+	return container
+}
+
+func TestContainer_Get_ProvideCycle(t *testing.T) {
+	container := newProvideCycleContainer()
+
+	withinTimeout(t, func() {
+		_, err := container.Get(di.StringRef("a"))
+		assertErrorType(t, err, di.ServiceBuildError)
+		assert.Contains(t, err.Error(), "circular dependency detected: a -> b -> a")
+	})
+}
+
+func TestContainer_Build_ParallelProvideCycle(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		container := newProvideCycleContainer()
+
+		withinTimeout(t, func() {
+			err := container.Build(di.WithParallelism(2))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "circular dependency detected")
+		})
+	}
+}
+
+func TestContainer_Get_ProviderPanics(t *testing.T) {
+	release := make(chan struct{})
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("panicking")).
+			Provider(func() *TestService1 {
+				<-release
+				panic("provider failed")
+			}),
+	)
+
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+
+	errs := make([]error, concurrentRequests)
+
+	withinTimeout(t, func() {
+		runConcurrently(concurrentRequests, func(i int) {
+			// the building request panics, all requests waiting for it receive an error.
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("%v", r) //nolint:goerr113
+				}
+			}()
+
+			_, errs[i] = container.Get(di.StringRef("panicking"))
+		})
+	})
+
+	for _, err := range errs {
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "provider failed")
+		}
+	}
+}
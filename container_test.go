@@ -2,6 +2,7 @@ package di_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/dtomasi/di"
 	"github.com/dtomasi/fakr"
@@ -224,7 +225,7 @@ func TestContainer_FindByTag(t *testing.T) {
 
 	container.Register(di.NewServiceDef(di.StringRef("no-provider")).Tags(di.StringRef("test")))
 	_, err = container.FindByTags([]fmt.Stringer{di.StringRef("test")})
-	assert.Error(t, err)
+	assertErrorType(t, err, di.ServiceBuildError)
 }
 
 func TestContainer_GetEventBus(t *testing.T) {
@@ -253,3 +254,154 @@ func TestContainer_Get(t *testing.T) {
 	_, err = container.Get(di.StringRef("no-provider"))
 	assert.Error(t, err)
 }
+
+func TestContainer_Get_ArgTypeMismatch(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("number")).
+			Provider(func(n int) int { return n }).
+			Args(di.InterfaceArg("x")),
+	)
+
+	var err error
+
+	assert.NotPanics(t, func() {
+		_, err = container.Get(di.StringRef("number"))
+	})
+	assertErrorType(t, err, di.CallableArgTypeMismatchError)
+	assert.Contains(t, err.Error(), "expected int got string")
+}
+
+func TestContainer_Get_ProviderWithoutReturnValue(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("nothing")).
+			Provider(func() {}),
+	)
+
+	_, err := container.Get(di.StringRef("nothing"))
+	assertErrorType(t, err, di.CallableToManyReturnValuesError)
+}
+
+func TestContainer_Get_OptionalServiceArg(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("consumer")).
+			Provider(func(tracer *closeRecorder, logger TestInterface) string {
+				if tracer == nil && logger == nil {
+					return "degraded"
+				}
+
+				return tracer.Name()
+			}).
+			Args(
+				di.OptionalServiceArg(di.StringRef("tracer")),
+				di.OptionalServiceArg(di.StringRef("logger")),
+			),
+	)
+
+	assert.NoError(t, container.Validate())
+
+	v, err := container.Get(di.StringRef("consumer"))
+	assert.NoError(t, err)
+	assert.Equal(t, "degraded", v)
+
+	container.Register(
+		di.NewServiceDef(di.StringRef("tracer")).
+			Provider(func() (*closeRecorder, error) {
+				return nil, errors.New("tracer failed") //nolint:goerr113
+			}),
+		di.NewServiceDef(di.StringRef("consumer2")).
+			Provider(func(tracer *closeRecorder) *closeRecorder { return tracer }).
+			Args(di.OptionalServiceArg(di.StringRef("tracer"))),
+	)
+
+	_, err = container.Get(di.StringRef("consumer2"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tracer failed")
+}
+
+type closeRecorder struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (r *closeRecorder) Name() string {
+	return r.name
+}
+
+func (r *closeRecorder) Close() error {
+	*r.closed = append(*r.closed, r.name)
+
+	return r.err
+}
+
+type contextCloseRecorder struct {
+	closeRecorder
+}
+
+func (r *contextCloseRecorder) Close(_ context.Context) error {
+	return r.closeRecorder.Close()
+}
+
+func TestContainer_Close(t *testing.T) {
+	var closed []string
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("db")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "db", closed: &closed} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("repo")).
+			Provider(func(_ *closeRecorder) *contextCloseRecorder {
+				return &contextCloseRecorder{closeRecorder{name: "repo", closed: &closed}} //nolint:exhaustivestruct
+			}).
+			Args(di.ServiceArg(di.StringRef("db"))),
+		di.NewServiceDef(di.StringRef("handler")).
+			Provider(func(repoName string) string { return "handler-" + repoName }).
+			Args(di.ServiceMethodCallArg(di.StringRef("repo"), "Name")).
+			Disposer(func(_ context.Context, instance interface{}) error {
+				closed = append(closed, instance.(string)) //nolint:forcetypeassert
+
+				return nil
+			}),
+		di.NewServiceDef(di.StringRef("lazy")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "lazy", closed: &closed} //nolint:exhaustivestruct
+			}),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"handler-repo", "repo", "db"}, closed)
+	assert.Error(t, container.GetContext().Err())
+}
+
+func TestContainer_Close_Error(t *testing.T) {
+	var closed []string
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("foo")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "foo", closed: &closed, err: errors.New("foo")} //nolint:goerr113
+			}),
+		di.NewServiceDef(di.StringRef("bar")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "bar", closed: &closed, err: errors.New("bar")} //nolint:goerr113
+			}),
+	)
+	container.Set(di.StringRef("external"), &closeRecorder{name: "external", closed: &closed}) //nolint:exhaustivestruct
+
+	assert.NoError(t, container.Build())
+
+	err := container.Close(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ContainerCloseError")
+	assert.Contains(t, err.Error(), "error while disposing service foo")
+	assert.Contains(t, err.Error(), "error while disposing service bar")
+	assert.ElementsMatch(t, []string{"foo", "bar"}, closed)
+}
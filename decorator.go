@@ -0,0 +1,214 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// DecoratorDef is a definition of a decorator that wraps a service instance after construction.
+type DecoratorDef struct {
+	ref       fmt.Stringer
+	decorator interface{}
+	args      []ServiceDefArg
+	priority  int
+	// err is set if the decorator cannot be called and is returned when the service is built
+	err error
+}
+
+// Priority sets the priority of the decorator. Decorators are applied in ascending priority, so the decorator
+// with the highest priority wraps all others. Decorators with the same priority are applied in the order they
+// were added. The default priority is 0. The priority must be set before the service is built.
+func (d *DecoratorDef) Priority(priority int) *DecoratorDef {
+	d.priority = priority
+
+	return d
+}
+
+// decoratorMap holds the decorators of a container by service ref.
+type decoratorMap struct {
+	mu         sync.RWMutex
+	decorators map[fmt.Stringer][]*DecoratorDef
+}
+
+func newDecoratorMap() *decoratorMap {
+	return &decoratorMap{
+		mu:         sync.RWMutex{},
+		decorators: map[fmt.Stringer][]*DecoratorDef{},
+	}
+}
+
+func (m *decoratorMap) add(d *DecoratorDef) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decorators[d.ref] = append(m.decorators[d.ref], d)
+}
+
+func (m *decoratorMap) load(ref fmt.Stringer) []*DecoratorDef {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]*DecoratorDef{}, m.decorators[ref]...)
+}
+
+// Decorate adds a decorator to the service with given ref.
+// The decorator is a function that receives the service instance followed by the evaluated args and returns the
+// instance that replaces it. Like a provider it may return an error as second return value.
+// Decorators are applied to each instance built by the provider of the service, also if the service is registered
+// after the decorator was added. Instances passed via Set are not decorated. If ref is an alias, the decorator is
+// added to the service the alias points to.
+// A decorator that is not a function or does not return the instance is rejected: building the service fails
+// and Validate reports it.
+func (c *Container) Decorate(ref fmt.Stringer, decorator interface{}, args ...ServiceDefArg) *DecoratorDef {
+	d := &DecoratorDef{
+		ref:       c.canonicalRef(ref),
+		decorator: decorator,
+		args:      args,
+		priority:  0,
+		err:       checkDecorator(decorator),
+	}
+
+	c.decorators.add(d)
+
+	return d
+}
+
+// checkDecorator returns an error if the decorator is not a function returning the instance and an optional error.
+func checkDecorator(decorator interface{}) error {
+	decoratorType := reflect.TypeOf(decorator)
+	if decoratorType == nil || decoratorType.Kind() != reflect.Func {
+		return z.NewWithOpts("decorator not a function", z.WithType(CallableNotAFuncError))
+	}
+
+	if decoratorType.NumOut() == 0 || decoratorType.NumOut() > 2 { //nolint:gomnd
+		return z.NewWithOpts(
+			fmt.Sprintf("decorator must have 1 or 2 return values (interface{}, error). Got %d",
+				decoratorType.NumOut(),
+			),
+			z.WithType(CallableToManyReturnValuesError),
+		)
+	}
+
+	return nil
+}
+
+// decoratorsOf returns the decorators for given ref in the order they are applied.
+// Decorators added to a parent container are inherited by its scopes.
+func (c *Container) decoratorsOf(ref fmt.Stringer) []*DecoratorDef {
+	var decorators []*DecoratorDef
+
+	if c.parent != nil {
+		decorators = c.parent.decoratorsOf(ref)
+	}
+
+	decorators = append(decorators, c.decorators.load(ref)...)
+
+	sort.SliceStable(decorators, func(i, j int) bool {
+		return decorators[i].priority < decorators[j].priority
+	})
+
+	return decorators
+}
+
+// decorate applies all decorators of given definition to the instance.
+func (c *Container) decorate(def *ServiceDef, instance interface{}) (interface{}, error) {
+	for i, d := range c.decoratorsOf(def.ref) {
+		if d.err != nil {
+			return nil, z.Wrapf(d.err, "could not apply decorator %d", i)
+		}
+
+		args := append([]ServiceDefArg{InterfaceArg(instance)}, d.args...)
+
+		decorated, err := c.callReflectValueWithArgs(reflect.ValueOf(d.decorator), args)
+		if err != nil {
+			return nil, z.Wrapf(err, "could not apply decorator %d", i)
+		}
+
+		instance = decorated
+	}
+
+	return instance, nil
+}
+
+// decoratorArgs returns the args of all decorators of given definition.
+func (c *Container) decoratorArgs(def *ServiceDef) []ServiceDefArg {
+	var args []ServiceDefArg
+
+	for _, d := range c.decoratorsOf(def.ref) {
+		args = append(args, d.args...)
+	}
+
+	return args
+}
+
+// serviceType returns the type of the service instance if it can be known without building the service.
+// For services that are not built yet, this is the type returned by the last decorator.
+func (c *Container) serviceType(def *ServiceDef) reflect.Type {
+	serviceType := def.producedType()
+	if def.getInstance() != nil || def.provider == nil {
+		return serviceType
+	}
+
+	for _, d := range c.decoratorsOf(def.ref) {
+		if decoratorType := reflect.TypeOf(d.decorator); decoratorType != nil &&
+			decoratorType.Kind() == reflect.Func && decoratorType.NumOut() > 0 {
+			serviceType = decoratorType.Out(0)
+		}
+	}
+
+	return serviceType
+}
+
+// validateDecorators checks the decorators of given definition like providers are checked.
+func (c *Container) validateDecorators(def *ServiceDef) (errs []error) {
+	// built instances are decorated already.
+	if def.getInstance() != nil {
+		return nil
+	}
+
+	instanceType := def.producedType()
+
+	for i, d := range c.decoratorsOf(def.ref) {
+		for _, err := range c.validateDecorator(d, instanceType) {
+			errs = append(errs, z.Wrapf(err, "invalid decorator %d", i))
+		}
+
+		if decoratorType := reflect.TypeOf(d.decorator); decoratorType != nil &&
+			decoratorType.Kind() == reflect.Func && decoratorType.NumOut() > 0 {
+			instanceType = decoratorType.Out(0)
+		}
+	}
+
+	return errs
+}
+
+// validateDecorator checks a decorator that receives an instance of given type.
+func (c *Container) validateDecorator(d *DecoratorDef, instanceType reflect.Type) (errs []error) {
+	if d.err != nil {
+		return append(errs, d.err)
+	}
+
+	decoratorType := reflect.TypeOf(d.decorator)
+
+	// the first parameter receives the service instance.
+	if decoratorType.NumIn() != len(d.args)+1 {
+		return append(errs, z.NewWithOpts(
+			fmt.Sprintf("decorator expects %d args got %d", decoratorType.NumIn(), len(d.args)+1),
+			z.WithType(CallableArgCountMismatchError),
+		))
+	}
+
+	if err := validateType(instanceType, decoratorType.In(0)); err != nil {
+		errs = append(errs, z.Wrap(err, "invalid instance arg"))
+	}
+
+	params := make([]reflect.Type, 0, len(d.args))
+	for i := 1; i < decoratorType.NumIn(); i++ {
+		params = append(params, decoratorType.In(i))
+	}
+
+	return append(errs, c.validateArgs(d.args, reflect.FuncOf(params, nil, false))...)
+}
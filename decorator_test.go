@@ -0,0 +1,166 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type salutation interface {
+	Greet() string
+}
+
+type baseSalutation struct{}
+
+func (g *baseSalutation) Greet() string {
+	return "hello"
+}
+
+type wrappingSalutation struct {
+	inner  salutation
+	prefix string
+}
+
+func (g *wrappingSalutation) Greet() string {
+	return fmt.Sprintf("%s(%s)", g.prefix, g.inner.Greet())
+}
+
+func newDecoratedContainer() *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("salutation")).
+			Provider(func() salutation {
+				return &baseSalutation{}
+			}),
+	)
+
+	return container
+}
+
+func TestContainer_Decorate(t *testing.T) {
+	container := newDecoratedContainer()
+	container.Set(di.StringRef("prefix"), "metrics")
+
+	container.Decorate(di.StringRef("salutation"), func(inner salutation, prefix string) salutation {
+		return &wrappingSalutation{inner: inner, prefix: prefix}
+	}, di.ServiceArg(di.StringRef("prefix")))
+
+	assert.NoError(t, container.Validate())
+
+	instance, err := di.Get[salutation](container, di.StringRef("salutation"))
+	assert.NoError(t, err)
+	assert.Equal(t, "metrics(hello)", instance.Greet())
+}
+
+func TestContainer_Decorate_Alias(t *testing.T) {
+	container := newDecoratedContainer()
+	container.Alias(di.StringRef("greeter"), di.StringRef("salutation"))
+
+	container.Decorate(di.StringRef("greeter"), func(inner salutation) salutation {
+		return &wrappingSalutation{inner: inner, prefix: "alias"}
+	})
+
+	instance, err := di.Get[salutation](container, di.StringRef("salutation"))
+	assert.NoError(t, err)
+	assert.Equal(t, "alias(hello)", instance.Greet())
+}
+
+func TestContainer_Decorate_NoReturnValue(t *testing.T) {
+	container := newDecoratedContainer()
+
+	container.Decorate(di.StringRef("salutation"), func(inner salutation) {})
+
+	err := container.Validate()
+	assertErrorType(t, err, di.ContainerValidationError)
+	assert.Contains(t, err.Error(), "decorator must have 1 or 2 return values")
+
+	_, err = container.Get(di.StringRef("salutation"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "[CallableToManyReturnValuesError]")
+}
+
+func TestContainer_Decorate_Priority(t *testing.T) {
+	container := newDecoratedContainer()
+
+	decorator := func(prefix string) func(inner salutation) salutation {
+		return func(inner salutation) salutation {
+			return &wrappingSalutation{inner: inner, prefix: prefix}
+		}
+	}
+
+	container.Decorate(di.StringRef("salutation"), decorator("outer")).Priority(10)
+	container.Decorate(di.StringRef("salutation"), decorator("first"))
+	container.Decorate(di.StringRef("salutation"), decorator("second"))
+	container.Decorate(di.StringRef("salutation"), decorator("inner")).Priority(-10)
+
+	instance, err := di.Get[salutation](container, di.StringRef("salutation"))
+	assert.NoError(t, err)
+	assert.Equal(t, "outer(second(first(inner(hello))))", instance.Greet())
+}
+
+func TestContainer_Decorate_Error(t *testing.T) {
+	container := newDecoratedContainer()
+
+	container.Decorate(di.StringRef("salutation"), func(inner salutation) (salutation, error) {
+		return nil, errors.New("decorator failed") //nolint:goerr113
+	})
+
+	_, err := container.Get(di.StringRef("salutation"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "decorator failed")
+}
+
+func TestContainer_Decorate_Scope(t *testing.T) {
+	container := newDecoratedContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("scoped")).
+			Opts(di.Scoped()).
+			Provider(func() salutation {
+				return &baseSalutation{}
+			}),
+	)
+
+	container.Decorate(di.StringRef("scoped"), func(inner salutation) salutation {
+		return &wrappingSalutation{inner: inner, prefix: "root"}
+	})
+
+	scope := container.NewScope(context.Background())
+	scope.Decorate(di.StringRef("scoped"), func(inner salutation) salutation {
+		return &wrappingSalutation{inner: inner, prefix: "scope"}
+	})
+
+	instance, err := di.Get[salutation](scope, di.StringRef("scoped"))
+	assert.NoError(t, err)
+	assert.Equal(t, "scope(root(hello))", instance.Greet())
+}
+
+func TestContainer_Decorate_Validate(t *testing.T) {
+	container := newDecoratedContainer()
+
+	container.Decorate(di.StringRef("salutation"), func(inner *wrappingSalutation) salutation {
+		return inner
+	})
+	container.Decorate(di.StringRef("salutation"), func(inner salutation, missing string) salutation {
+		return inner
+	}, di.ServiceArg(di.StringRef("missing")))
+	container.Decorate(di.StringRef("salutation"), "not a function")
+
+	err := container.Validate()
+	assertErrorType(t, err, di.ContainerValidationError)
+	assert.Contains(t, err.Error(), "referenced service missing not found")
+	assert.Contains(t, err.Error(), "decorator not a function")
+}
+
+func TestContainer_Decorate_CircularDependency(t *testing.T) {
+	container := newDecoratedContainer()
+
+	container.Decorate(di.StringRef("salutation"), func(inner salutation, self salutation) salutation {
+		return inner
+	}, di.ServiceArg(di.StringRef("salutation")))
+
+	_, err := container.Get(di.StringRef("salutation"))
+	assertErrorType(t, err, di.CircularDependencyError)
+}
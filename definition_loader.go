@@ -0,0 +1,238 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// definitionsDocument describes the structure of a definitions file.
+type definitionsDocument struct {
+	Services []serviceDefinition `yaml:"services"`
+}
+
+// serviceDefinition describes a single service of a definitions file.
+type serviceDefinition struct {
+	Ref      string      `yaml:"ref"`
+	Provider string      `yaml:"provider"`
+	Args     []yaml.Node `yaml:"args"`
+	Tags     []string    `yaml:"tags"`
+	Options  []string    `yaml:"options"`
+}
+
+// serviceOptionsByName maps the option names usable in definition files to service options.
+var serviceOptionsByName = map[string]func() ServiceOption{ //nolint:gochecknoglobals
+	"BuildOnFirstRequest": BuildOnFirstRequest,
+	"BuildAlwaysRebuild":  BuildAlwaysRebuild,
+	"Autowire":            Autowire,
+	"Scoped":              Scoped,
+}
+
+// LoadDefinitions reads service definitions from a YAML or JSON document.
+// Providers are referenced by name and resolved using given ProviderRegistry. Service refs and tags are
+// created as StringRef. Arguments are either literal values or mappings with one of the following keys:
+//
+//	args:
+//	  - service: logger                   # ServiceArg
+//	  - service: repo                     # ServiceMethodCallArg
+//	    method: Find
+//	    args: ["foo"]
+//	  - service: tracer                   # OptionalServiceArg
+//	    optional: true
+//	  - param: db.host                    # ParamArg
+//	  - param: db.port                    # ParamArgWithDefault
+//	    default: 5432
+//	  - param: db.user                    # OptionalParamArg
+//	    optional: true
+//	  - tagged: [handler]                 # ServicesByTagsArg
+//	  - inject: context                   # ContextArg, "container" and "eventbus" are supported as well
+//	  - value: {foo: bar}                 # InterfaceArg, for literals that are mappings
+//
+// Example:
+//
+//	services:
+//	  - ref: greeter
+//	    provider: NewGreeter
+//	    args:
+//	      - inject: context
+//	      - param: salutation
+//	    tags: [greeter]
+//	    options: [BuildOnFirstRequest]
+func LoadDefinitions(r io.Reader, registry *ProviderRegistry) (defs []*ServiceDef, err error) {
+	defer z.WrapPtrWithOpts(&err, "error while loading service definitions", z.WithType(DefinitionLoadError))
+
+	var doc definitionsDocument
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&doc); err != nil && err != io.EOF { //nolint:errorlint
+		return nil, err
+	}
+
+	for _, sd := range doc.Services {
+		def, defErr := sd.toServiceDef(registry)
+		if defErr != nil {
+			return nil, defErr
+		}
+
+		defs = append(defs, def)
+	}
+
+	return defs, nil
+}
+
+func (sd *serviceDefinition) toServiceDef(registry *ProviderRegistry) (*ServiceDef, error) {
+	if sd.Ref == "" {
+		return nil, z.NewWithOpts("service definition without ref", z.WithType(DefinitionLoadError))
+	}
+
+	def := NewServiceDef(StringRef(sd.Ref))
+
+	if sd.Provider != "" {
+		provider, ok := registry.Lookup(sd.Provider)
+		if !ok {
+			return nil, z.NewWithOpts(
+				fmt.Sprintf("provider %s of service %s is not registered", sd.Provider, sd.Ref),
+				z.WithType(ProviderNotRegisteredError),
+			)
+		}
+
+		def.Provider(provider)
+	}
+
+	args, err := parseArgNodes(sd.Args)
+	if err != nil {
+		return nil, z.Wrapf(err, "invalid args of service %s", sd.Ref)
+	}
+
+	def.Args(args...)
+	def.Tags(stringRefs(sd.Tags)...)
+
+	for _, name := range sd.Options {
+		opt, ok := serviceOptionsByName[name]
+		if !ok {
+			return nil, z.NewWithOpts(
+				fmt.Sprintf("unknown option %s of service %s", name, sd.Ref),
+				z.WithType(DefinitionLoadError),
+			)
+		}
+
+		def.Opts(opt())
+	}
+
+	return def, nil
+}
+
+func parseArgNodes(nodes []yaml.Node) ([]ServiceDefArg, error) {
+	args := make([]ServiceDefArg, 0, len(nodes))
+
+	for i := range nodes {
+		arg, err := parseArgNode(&nodes[i])
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// parseArgNode creates a ServiceDefArg from a yaml node. See LoadDefinitions for the supported notations.
+func parseArgNode(node *yaml.Node) (ServiceDefArg, error) {
+	if node.Kind != yaml.MappingNode {
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		return InterfaceArg(value), nil
+	}
+
+	fields := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+
+	switch {
+	case fields["value"] != nil && len(fields) == 1:
+		var value interface{}
+		if err := fields["value"].Decode(&value); err != nil {
+			return nil, err
+		}
+
+		return InterfaceArg(value), nil
+	case fields["service"] != nil && fields["method"] != nil &&
+		(len(fields) == 2 || len(fields) == 3 && fields["args"] != nil):
+		var methodArgs []yaml.Node
+		if argsNode := fields["args"]; argsNode != nil {
+			if err := argsNode.Decode(&methodArgs); err != nil {
+				return nil, err
+			}
+		}
+
+		args, err := parseArgNodes(methodArgs)
+		if err != nil {
+			return nil, err
+		}
+
+		return ServiceMethodCallArg(StringRef(fields["service"].Value), fields["method"].Value, args...), nil
+	case fields["service"] != nil && len(fields) == 1:
+		return ServiceArg(StringRef(fields["service"].Value)), nil
+	case fields["service"] != nil && fields["optional"] != nil && len(fields) == 2:
+		var optional bool
+		if err := fields["optional"].Decode(&optional); err != nil || !optional {
+			return ServiceArg(StringRef(fields["service"].Value)), err
+		}
+
+		return OptionalServiceArg(StringRef(fields["service"].Value)), nil
+	case fields["param"] != nil && len(fields) == 1:
+		return ParamArg(fields["param"].Value), nil
+	case fields["param"] != nil && fields["default"] != nil && len(fields) == 2:
+		var value interface{}
+		if err := fields["default"].Decode(&value); err != nil {
+			return nil, err
+		}
+
+		return ParamArgWithDefault(fields["param"].Value, value), nil
+	case fields["param"] != nil && fields["optional"] != nil && len(fields) == 2:
+		var optional bool
+		if err := fields["optional"].Decode(&optional); err != nil || !optional {
+			return ParamArg(fields["param"].Value), err
+		}
+
+		return OptionalParamArg(fields["param"].Value), nil
+	case fields["tagged"] != nil && len(fields) == 1:
+		var tags []string
+		if err := fields["tagged"].Decode(&tags); err != nil {
+			return nil, err
+		}
+
+		return ServicesByTagsArg(stringRefs(tags)), nil
+	case fields["inject"] != nil && len(fields) == 1:
+		switch fields["inject"].Value {
+		case "context":
+			return ContextArg(), nil
+		case "container":
+			return ContainerArg(), nil
+		case "eventbus":
+			return EventBusArg(), nil
+		}
+	}
+
+	return nil, z.NewWithOpts(
+		fmt.Sprintf("unsupported argument at line %d", node.Line),
+		z.WithType(DefinitionLoadError),
+	)
+}
+
+func stringRefs(names []string) []fmt.Stringer {
+	refs := make([]fmt.Stringer, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, StringRef(name))
+	}
+
+	return refs
+}
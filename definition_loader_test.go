@@ -0,0 +1,118 @@
+package di_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const testDefinitionsYAML = `
+services:
+  - ref: TestService1
+    provider: NewTestService1
+    args:
+      - inject: context
+      - inject: container
+      - true
+      - param: foo.bar.baz
+    tags: [foo]
+  - ref: TestService2
+    provider: NewTestService2
+    args:
+      - service: TestService1
+      - tagged: [foo]
+      - value: true
+      - param: foo.bar.baz
+      - service: TestService1
+        method: TestFactoryMethod
+        args: [test-service]
+    tags: [test]
+    options: [BuildOnFirstRequest]
+`
+
+const testDefinitionsJSON = `{
+  "services": [
+    {
+      "ref": "TestService1",
+      "provider": "NewTestService1",
+      "args": [{"inject": "context"}, {"inject": "container"}, false, "json"]
+    }
+  ]
+}`
+
+func newTestProviderRegistry() *di.ProviderRegistry {
+	return di.NewProviderRegistry().
+		Register("NewTestService1", NewTestService1).
+		Register("NewTestService2", NewTestService2)
+}
+
+func TestLoadDefinitions_YAML(t *testing.T) {
+	defs, err := di.LoadDefinitions(strings.NewReader(testDefinitionsYAML), newTestProviderRegistry())
+	assert.NoError(t, err)
+	assert.Len(t, defs, 2)
+
+	container := di.NewServiceContainer(di.WithParameterProvider(&ParameterProviderMock{}))
+	container.Register(defs...)
+	assert.NoError(t, container.Build())
+
+	t2 := di.MustGet[*TestService2](container, di.StringRef("TestService2"))
+	assert.True(t, t2.True())
+	assert.Equal(t, "foo", t2.TestString())
+	assert.Equal(t, "Hello test-service", t2.testStringFromFactory)
+}
+
+func TestLoadDefinitions_JSON(t *testing.T) {
+	defs, err := di.LoadDefinitions(strings.NewReader(testDefinitionsJSON), newTestProviderRegistry())
+	assert.NoError(t, err)
+
+	container := di.NewServiceContainer()
+	container.Register(defs...)
+
+	t1 := di.MustGet[*TestService1](container, di.StringRef("TestService1"))
+	assert.False(t, t1.True())
+	assert.Equal(t, "json", t1.TestString())
+	assert.Equal(t, container, t1.Container())
+}
+
+func TestLoadDefinitions_OptionalArgs(t *testing.T) {
+	doc := `
+services:
+  - ref: TestService1
+    provider: NewTestService1
+    args:
+      - inject: context
+      - optional: true
+        service: missing
+      - param: is.true
+        default: "true"
+      - param: test.string
+        optional: true
+`
+
+	defs, err := di.LoadDefinitions(strings.NewReader(doc), newTestProviderRegistry())
+	assert.NoError(t, err)
+
+	container := di.NewServiceContainer()
+	container.Register(defs...)
+
+	t1 := di.MustGet[*TestService1](container, di.StringRef("TestService1"))
+	assert.Nil(t, t1.Container())
+	assert.True(t, t1.True())
+	assert.Equal(t, "", t1.TestString())
+}
+
+func TestLoadDefinitions_Errors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"provider not registered": "services: [{ref: foo, provider: NewFoo}]",
+		"missing ref":             "services: [{provider: NewTestService1}]",
+		"unknown option":          "services: [{ref: foo, options: [Foo]}]",
+		"unknown argument":        "services: [{ref: foo, args: [{foo: bar}]}]",
+		"unknown field":           "services: [{ref: foo, foo: bar}]",
+		"invalid document":        "services: foo",
+	} {
+		_, err := di.LoadDefinitions(strings.NewReader(doc), newTestProviderRegistry())
+		assert.Error(t, err, name)
+		assertErrorType(t, err, di.DefinitionLoadError)
+	}
+}
@@ -0,0 +1,216 @@
+package di
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// serviceDependent is implemented by arguments that reference other services.
+// It is used to derive the dependency graph of the container without evaluating any argument.
+type serviceDependent interface {
+	dependencies(c *Container) []fmt.Stringer
+}
+
+// argDependencies returns the refs of all services that are referenced by given args.
+func argDependencies(c *Container, args []ServiceDefArg) []fmt.Stringer {
+	var refs []fmt.Stringer
+
+	for _, arg := range args {
+		if d, ok := arg.(serviceDependent); ok {
+			refs = append(refs, d.dependencies(c)...)
+		}
+	}
+
+	return refs
+}
+
+// joinArgs returns a new slice holding the args of all given slices.
+// Appending to the args of a definition directly could write into their backing array, which is shared by all
+// concurrent readers of the definition.
+func joinArgs(argLists ...[]ServiceDefArg) []ServiceDefArg {
+	n := 0
+	for _, args := range argLists {
+		n += len(args)
+	}
+
+	joined := make([]ServiceDefArg, 0, n)
+	for _, args := range argLists {
+		joined = append(joined, args...)
+	}
+
+	return joined
+}
+
+// dependenciesOf returns the refs of all services the given definition depends on.
+// Dependencies of autowired parameters are included as far as they can be resolved, as well as the dependencies
+// of decorators. Aliases are resolved to the services they point to.
+func (c *Container) dependenciesOf(def *ServiceDef) []fmt.Stringer {
+	args, _ := c.resolveArgs(def)
+
+	deps := argDependencies(c, joinArgs(args, c.decoratorArgs(def)))
+	for i, dep := range deps {
+		deps[i] = c.canonicalRef(dep)
+	}
+
+	return deps
+}
+
+// Dependencies returns the refs of all services the referenced service depends on directly, including the
+// services referenced by its calls. It returns nil if the service is not registered.
+func (c *Container) Dependencies(ref fmt.Stringer) []fmt.Stringer {
+	def, ok := c.loadServiceDef(ref)
+	if !ok {
+		return nil
+	}
+
+	deps := c.dependenciesOf(def)
+	for _, dep := range argDependencies(c, callArgs(def)) {
+		deps = append(deps, c.canonicalRef(dep))
+	}
+
+	return deps
+}
+
+// findCycle returns the chain of refs forming a cycle that is reachable from given ref.
+// The first and the last element of the chain are the same ref. If there is no cycle nil is returned.
+func (c *Container) findCycle(ref fmt.Stringer) []fmt.Stringer {
+	var path []fmt.Stringer
+
+	onPath := map[fmt.Stringer]bool{}
+	done := map[fmt.Stringer]bool{}
+
+	var visit func(ref fmt.Stringer) []fmt.Stringer
+	visit = func(ref fmt.Stringer) []fmt.Stringer {
+		if onPath[ref] {
+			for i, r := range path {
+				if r == ref {
+					return append(append([]fmt.Stringer{}, path[i:]...), ref)
+				}
+			}
+		}
+
+		if done[ref] {
+			return nil
+		}
+
+		if def, ok := c.loadServiceDef(ref); ok {
+			onPath[ref] = true
+			path = append(path, ref)
+
+			for _, dep := range c.dependenciesOf(def) {
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+
+			path = path[:len(path)-1]
+			onPath[ref] = false
+		}
+
+		done[ref] = true
+
+		return nil
+	}
+
+	return visit(ref)
+}
+
+// formatRefChain formats a chain of refs as "A -> B -> C".
+func formatRefChain(refs []fmt.Stringer) string {
+	return strings.Join(refNames(refs), " -> ")
+}
+
+// refNames returns the string representations of given refs.
+func refNames(refs []fmt.Stringer) []string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.String())
+	}
+
+	return names
+}
+
+// sortByDependencies returns given refs ordered so that dependencies come before their dependents.
+// Services referenced by calls are ordered like dependencies, unless they depend on the calling service themselves.
+// Refs are visited sorted by name to get a stable order for independent services.
+func (c *Container) sortByDependencies(refs []fmt.Stringer) []fmt.Stringer {
+	sorted := make([]fmt.Stringer, 0, len(refs))
+	wanted := make(map[fmt.Stringer]bool, len(refs))
+	visited := map[fmt.Stringer]bool{}
+
+	for _, ref := range refs {
+		wanted[ref] = true
+	}
+
+	var visit func(ref fmt.Stringer)
+	visit = func(ref fmt.Stringer) {
+		if visited[ref] {
+			return
+		}
+
+		visited[ref] = true
+
+		if def, ok := c.loadServiceDef(ref); ok {
+			for _, dep := range c.dependenciesOf(def) {
+				visit(dep)
+			}
+
+			for _, dep := range argDependencies(c, callArgs(def)) {
+				if dep = c.canonicalRef(dep); !c.dependsOn(dep, ref) {
+					visit(dep)
+				}
+			}
+		}
+
+		if wanted[ref] {
+			sorted = append(sorted, ref)
+		}
+	}
+
+	for _, ref := range sortRefs(refs) {
+		visit(ref)
+	}
+
+	return sorted
+}
+
+// dependsOn reports whether the service from depends on the service to directly or indirectly, including the
+// services referenced by calls.
+func (c *Container) dependsOn(from fmt.Stringer, to fmt.Stringer) bool {
+	visited := map[fmt.Stringer]bool{}
+
+	var visit func(ref fmt.Stringer) bool
+	visit = func(ref fmt.Stringer) bool {
+		if ref == to {
+			return true
+		}
+
+		if visited[ref] {
+			return false
+		}
+
+		visited[ref] = true
+
+		for _, dep := range c.Dependencies(ref) {
+			if visit(dep) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return visit(from)
+}
+
+// sortRefs returns a copy of given refs sorted by their string representation.
+func sortRefs(refs []fmt.Stringer) []fmt.Stringer {
+	sorted := append([]fmt.Stringer{}, refs...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	return sorted
+}
@@ -0,0 +1,105 @@
+package di_test
+
+import (
+	"fmt"
+	"github.com/dtomasi/di"
+	z "github.com/dtomasi/zerrors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func assertErrorType(t *testing.T, err error, errType di.ErrorType) {
+	t.Helper()
+
+	typedErr, ok := err.(z.TypeAwareError) //nolint:errorlint
+	if assert.True(t, ok, "error is not type aware: %v", err) {
+		assert.True(t, typedErr.IsType(errType), "expected error type %s: %v", errType, err)
+	}
+}
+
+func TestContainer_Get_CircularDependency(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("A")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("B"))),
+		di.NewServiceDef(di.StringRef("B")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("C"))),
+		di.NewServiceDef(di.StringRef("C")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("A"))),
+	)
+
+	_, err := container.Get(di.StringRef("A"))
+	assert.Error(t, err)
+	assertErrorType(t, err, di.CircularDependencyError)
+	assert.Contains(t, err.Error(), "A -> B -> C -> A")
+
+	_, err = container.Get(di.StringRef("B"))
+	assert.Contains(t, err.Error(), "B -> C -> A -> B")
+}
+
+func TestContainer_Get_CircularDependency_MethodCallArg(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("A")).
+			Provider(func(s string) *TestService1 { return &TestService1{testString: s} }). //nolint:exhaustivestruct
+			Args(di.ServiceMethodCallArg(di.StringRef("B"), "TestString")),
+		di.NewServiceDef(di.StringRef("B")).
+			Provider(func(s string) *TestService1 { return &TestService1{testString: s} }). //nolint:exhaustivestruct
+			Args(di.ServiceMethodCallArg(di.StringRef("A"), "TestString")),
+	)
+
+	_, err := container.Get(di.StringRef("A"))
+	assertErrorType(t, err, di.CircularDependencyError)
+	assert.Contains(t, err.Error(), "A -> B -> A")
+}
+
+func TestContainer_Get_CircularDependency_ServicesByTagsArg(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("registry")).
+			Provider(func(handlers []interface{}) []interface{} { return handlers }).
+			Args(di.ServicesByTagsArg([]fmt.Stringer{di.StringRef("handler")})),
+		di.NewServiceDef(di.StringRef("handler")).
+			Provider(func(registry []interface{}) string { return "handler" }).
+			Args(di.ServiceArg(di.StringRef("registry"))).
+			Tags(di.StringRef("handler")),
+	)
+
+	err := container.Build()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "registry -> handler -> registry")
+}
+
+func TestContainer_Graph_DoesNotModifyArgs(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Set(di.StringRef("a"), "a")
+	container.Set(di.StringRef("b"), "b")
+	container.Set(di.StringRef("c"), "c")
+	container.Set(di.StringRef("decorator"), "decorator")
+
+	// adding args one by one leaves spare capacity in their backing array, which must not be written to
+	container.Register(
+		di.NewServiceDef(di.StringRef("service")).
+			Provider(func(a, b, c string) string { return a + b + c }).
+			Args(di.ServiceArg(di.StringRef("a"))).
+			Args(di.ServiceArg(di.StringRef("b"))).
+			Args(di.ServiceArg(di.StringRef("c"))),
+	)
+	container.Decorate(di.StringRef("service"), func(s string, d string) string {
+		return s + d
+	}, di.ServiceArg(di.StringRef("decorator")))
+
+	// the race detector reports concurrent writes to the args
+	runConcurrently(concurrentRequests, func(i int) {
+		if i%2 == 0 {
+			assert.NotEmpty(t, container.Graph().Edges)
+		} else {
+			assert.NoError(t, container.Validate())
+		}
+	})
+
+	assert.Equal(t, "abcdecorator", container.MustGet(di.StringRef("service")))
+}
@@ -0,0 +1,31 @@
+package di
+
+import (
+	"context"
+	"io"
+)
+
+// DisposerFunc defines a function that tears down a service instance when the container is closed.
+type DisposerFunc func(ctx context.Context, instance interface{}) error
+
+// contextCloser is implemented by services that accept a context while closing.
+type contextCloser interface {
+	Close(ctx context.Context) error
+}
+
+// disposeInstance tears down a single service instance.
+// A disposer defined on the ServiceDef takes precedence over the Close methods of the instance.
+func disposeInstance(ctx context.Context, def *ServiceDef, instance interface{}) error {
+	if def.disposer != nil {
+		return def.disposer(ctx, instance)
+	}
+
+	switch closer := instance.(type) {
+	case contextCloser:
+		return closer.Close(ctx)
+	case io.Closer:
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package ditest
+
+import (
+	"fmt"
+	"github.com/dtomasi/di"
+	"testing"
+)
+
+// AssertBuilt asserts that the referenced service is built.
+func AssertBuilt(t testing.TB, c *di.Container, ref fmt.Stringer) bool {
+	t.Helper()
+
+	def, ok := c.ServiceDefs().Load(ref)
+	if !ok {
+		t.Errorf("service %s is not registered", ref)
+
+		return false
+	}
+
+	if !def.IsBuilt() {
+		t.Errorf("expected service %s to be built", ref)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertNotBuilt asserts that the referenced service is registered but not built.
+func AssertNotBuilt(t testing.TB, c *di.Container, ref fmt.Stringer) bool {
+	t.Helper()
+
+	def, ok := c.ServiceDefs().Load(ref)
+	if !ok {
+		t.Errorf("service %s is not registered", ref)
+
+		return false
+	}
+
+	if def.IsBuilt() {
+		t.Errorf("expected service %s not to be built", ref)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertDependsOn asserts that the referenced service depends on dep directly or indirectly.
+func AssertDependsOn(t testing.TB, c *di.Container, ref fmt.Stringer, dep fmt.Stringer) bool {
+	t.Helper()
+
+	visited := map[fmt.Stringer]bool{ref: true}
+	queue := []fmt.Stringer{ref}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, d := range c.Dependencies(current) {
+			if d == dep {
+				return true
+			}
+
+			if !visited[d] {
+				visited[d] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	t.Errorf("expected service %s to depend on %s", ref, dep)
+
+	return false
+}
@@ -0,0 +1,53 @@
+package ditest_test
+
+import (
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/dtomasi/di/ditest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// recordingT records errors instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertBuilt(t *testing.T) {
+	c := newContainer()
+	_ = di.MustGet[*repository](c, di.StringRef("repository"))
+
+	rt := &recordingT{TB: t}
+
+	assert.True(t, ditest.AssertBuilt(rt, c, di.StringRef("repository")))
+	assert.True(t, ditest.AssertNotBuilt(rt, c, di.StringRef("handler")))
+	assert.Empty(t, rt.errors)
+
+	assert.False(t, ditest.AssertBuilt(rt, c, di.StringRef("handler")))
+	assert.False(t, ditest.AssertNotBuilt(rt, c, di.StringRef("dsn")))
+	assert.False(t, ditest.AssertBuilt(rt, c, di.StringRef("missing")))
+	assert.Equal(t, []string{
+		"expected service handler to be built",
+		"expected service dsn not to be built",
+		"service missing is not registered",
+	}, rt.errors)
+}
+
+func TestAssertDependsOn(t *testing.T) {
+	c := newContainer()
+	rt := &recordingT{TB: t}
+
+	assert.True(t, ditest.AssertDependsOn(rt, c, di.StringRef("handler"), di.StringRef("repository")))
+	assert.True(t, ditest.AssertDependsOn(rt, c, di.StringRef("handler"), di.StringRef("dsn")))
+	assert.Empty(t, rt.errors)
+
+	assert.False(t, ditest.AssertDependsOn(rt, c, di.StringRef("handler"), di.StringRef("clock")))
+	assert.Equal(t, []string{"expected service handler to depend on clock"}, rt.errors)
+}
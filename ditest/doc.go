@@ -0,0 +1,2 @@
+// Package ditest provides helpers to test code that is wired by a di.Container.
+package ditest
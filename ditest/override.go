@@ -0,0 +1,66 @@
+package ditest
+
+import (
+	"fmt"
+	"github.com/dtomasi/di"
+	"testing"
+)
+
+// Override replaces the service definition for ref by given instance until the test and all its subtests completed.
+// All built services that depend on ref directly or indirectly are replaced by unbuilt copies of their
+// definitions, so they are built again with the instance on next request. The replaced definitions itself are
+// not changed and are restored by Isolate once the test completed.
+//
+// The replaced instances are neither stopped nor disposed, as they are still in use by the restored container.
+// Instances that are built from the copies while overridden are orphaned when the container is restored: they are
+// not stopped or disposed by Close, so tests have to clean them up on their own if needed.
+func Override(t testing.TB, c *di.Container, ref fmt.Stringer, instance interface{}) {
+	t.Helper()
+
+	Isolate(t, c)
+
+	defs := c.ServiceDefs()
+
+	for _, dependent := range dependents(c, ref) {
+		if def, ok := defs.Load(dependent); ok && def.IsBuilt() {
+			defs.Store(dependent, def.Clone())
+		}
+	}
+
+	c.Set(ref, instance)
+}
+
+// dependents returns the refs of all services that depend on ref directly or indirectly.
+func dependents(c *di.Container, ref fmt.Stringer) []fmt.Stringer {
+	reverse := map[fmt.Stringer][]fmt.Stringer{}
+
+	_ = c.ServiceDefs().Range(func(key fmt.Stringer, _ *di.ServiceDef) error {
+		for _, dep := range c.Dependencies(key) {
+			reverse[dep] = append(reverse[dep], key)
+		}
+
+		return nil
+	})
+
+	var result []fmt.Stringer
+
+	visited := map[fmt.Stringer]bool{ref: true}
+	queue := []fmt.Stringer{ref}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range reverse[current] {
+			if visited[dependent] {
+				continue
+			}
+
+			visited[dependent] = true
+			result = append(result, dependent)
+			queue = append(queue, dependent)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,69 @@
+package ditest_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/dtomasi/di/ditest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type repository struct {
+	dsn string
+}
+
+type handler struct {
+	repo *repository
+}
+
+// newContainer returns a container with a handler depending on a repository depending on a dsn.
+func newContainer() *di.Container {
+	c := di.NewServiceContainer()
+	c.Register(
+		di.NewServiceDef(di.StringRef("dsn")).
+			Provider(func() string { return "postgres://db" }),
+		di.NewServiceDef(di.StringRef("repository")).
+			Provider(func(dsn string) *repository { return &repository{dsn: dsn} }).
+			Args(di.ServiceArg(di.StringRef("dsn"))),
+		di.NewServiceDef(di.StringRef("handler")).
+			Provider(func(repo *repository) *handler { return &handler{repo: repo} }).
+			Args(di.ServiceArg(di.StringRef("repository"))),
+		di.NewServiceDef(di.StringRef("clock")).
+			Provider(func() string { return "clock" }),
+	)
+
+	return c
+}
+
+func TestOverride(t *testing.T) {
+	c := newContainer()
+	assert.NoError(t, c.Build())
+
+	before := di.MustGet[*handler](c, di.StringRef("handler"))
+
+	ditest.Override(t, c, di.StringRef("dsn"), "sqlite://memory")
+
+	ditest.AssertNotBuilt(t, c, di.StringRef("repository"))
+	ditest.AssertNotBuilt(t, c, di.StringRef("handler"))
+	ditest.AssertBuilt(t, c, di.StringRef("clock"))
+
+	after := di.MustGet[*handler](c, di.StringRef("handler"))
+	assert.NotSame(t, before, after)
+	assert.Equal(t, "sqlite://memory", after.repo.dsn)
+	assert.Equal(t, "postgres://db", before.repo.dsn)
+}
+
+func TestOverride_RestoresAfterTest(t *testing.T) {
+	c := newContainer()
+	assert.NoError(t, c.Build())
+
+	before := di.MustGet[*handler](c, di.StringRef("handler"))
+
+	t.Run("override", func(t *testing.T) {
+		ditest.Override(t, c, di.StringRef("dsn"), "sqlite://memory")
+
+		assert.Equal(t, "sqlite://memory", di.MustGet[*handler](c, di.StringRef("handler")).repo.dsn)
+	})
+
+	assert.Same(t, before, di.MustGet[*handler](c, di.StringRef("handler")))
+	assert.Equal(t, "postgres://db", di.MustGet[string](c, di.StringRef("dsn")))
+}
@@ -0,0 +1,56 @@
+package ditest
+
+import (
+	"fmt"
+	"github.com/dtomasi/di"
+	"testing"
+)
+
+// State is the state of the service definitions of a container at the time Snapshot was called.
+type State struct {
+	c     *di.Container
+	defs  map[fmt.Stringer]*di.ServiceDef
+	built map[fmt.Stringer]bool
+}
+
+// Snapshot records the service definitions of the container and which of them are built.
+func Snapshot(c *di.Container) *State {
+	s := &State{c: c, defs: map[fmt.Stringer]*di.ServiceDef{}, built: map[fmt.Stringer]bool{}}
+
+	_ = c.ServiceDefs().Range(func(key fmt.Stringer, def *di.ServiceDef) error {
+		s.defs[key] = def
+		s.built[key] = def.IsBuilt()
+
+		return nil
+	})
+
+	return s
+}
+
+// Restore resets the service definitions of the container to the recorded state.
+// Definitions that were registered afterwards are removed, and services that were not built at the time of the
+// snapshot are built again on next request. Instances are not disposed.
+func (s *State) Restore() {
+	defs := s.c.ServiceDefs()
+	defs.Clear()
+
+	for ref, def := range s.defs {
+		if s.built[ref] {
+			defs.Store(ref, def)
+
+			continue
+		}
+
+		defs.Store(ref, def.Clone())
+	}
+}
+
+// Isolate takes a snapshot of the container that is restored when the test and all its subtests completed.
+func Isolate(t testing.TB, c *di.Container) *State {
+	t.Helper()
+
+	s := Snapshot(c)
+	t.Cleanup(s.Restore)
+
+	return s
+}
@@ -0,0 +1,43 @@
+package ditest_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/dtomasi/di/ditest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSnapshot_Restore(t *testing.T) {
+	c := newContainer()
+	original := di.MustGet[*repository](c, di.StringRef("repository"))
+
+	state := ditest.Snapshot(c)
+
+	ditest.Override(t, c, di.StringRef("dsn"), "sqlite://memory")
+	c.Set(di.StringRef("extra"), "extra")
+	_ = di.MustGet[*handler](c, di.StringRef("handler"))
+
+	state.Restore()
+
+	_, err := c.Get(di.StringRef("extra"))
+	assert.Error(t, err)
+
+	ditest.AssertNotBuilt(t, c, di.StringRef("handler"))
+	assert.Same(t, original, di.MustGet[*handler](c, di.StringRef("handler")).repo)
+}
+
+func TestIsolate(t *testing.T) {
+	c := newContainer()
+
+	t.Run("set", func(t *testing.T) {
+		ditest.Isolate(t, c)
+		c.Set(di.StringRef("dsn"), "sqlite://memory")
+
+		assert.Equal(t, "sqlite://memory", di.MustGet[*repository](c, di.StringRef("repository")).dsn)
+	})
+
+	t.Run("restored", func(t *testing.T) {
+		ditest.AssertNotBuilt(t, c, di.StringRef("repository"))
+		assert.Equal(t, "postgres://db", di.MustGet[*repository](c, di.StringRef("repository")).dsn)
+	})
+}
@@ -0,0 +1,118 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// EnvParameterProvider is a ParameterProvider that reads parameters from environment variables.
+// A dotted parameter path like db.pool.size is mapped to an env var name by joining the prefix and the path
+// segments with the separator and applying the key transform, e.g. APP_DB_POOL_SIZE.
+// Values are returned as strings. Set writes into an overlay that takes precedence over the environment,
+// the environment itself is never modified.
+type EnvParameterProvider struct {
+	prefix       string
+	separator    string
+	keyTransform func(key string) string
+
+	// mu guards overlay
+	mu      sync.RWMutex
+	overlay map[string]interface{}
+}
+
+// EnvParameterProviderOption defines an option for the EnvParameterProvider.
+type EnvParameterProviderOption func(p *EnvParameterProvider)
+
+// WithEnvPrefix sets the prefix that is added to all env var names. There is no prefix by default.
+func WithEnvPrefix(prefix string) EnvParameterProviderOption {
+	return func(p *EnvParameterProvider) {
+		p.prefix = prefix
+	}
+}
+
+// WithEnvSeparator sets the separator that is used to join the prefix and the path segments. Defaults to "_".
+func WithEnvSeparator(separator string) EnvParameterProviderOption {
+	return func(p *EnvParameterProvider) {
+		p.separator = separator
+	}
+}
+
+// WithEnvKeyTransform sets the function that turns the joined key into the env var name.
+// By default, the key is upper-cased and all characters that are not allowed in env var names are replaced by "_".
+func WithEnvKeyTransform(fn func(key string) string) EnvParameterProviderOption {
+	return func(p *EnvParameterProvider) {
+		p.keyTransform = fn
+	}
+}
+
+// NewEnvParameterProvider returns a new EnvParameterProvider instance.
+func NewEnvParameterProvider(opts ...EnvParameterProviderOption) *EnvParameterProvider {
+	p := &EnvParameterProvider{
+		prefix:       "",
+		separator:    "_",
+		keyTransform: defaultEnvKeyTransform,
+		mu:           sync.RWMutex{},
+		overlay:      map[string]interface{}{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Get returns the value that was set for the path or the value of the env var the path maps to.
+func (p *EnvParameterProvider) Get(path string) (interface{}, error) {
+	p.mu.RLock()
+	value, ok := p.overlay[path]
+	p.mu.RUnlock()
+
+	if ok {
+		return value, nil
+	}
+
+	name := p.EnvName(path)
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+
+	return nil, z.NewWithOpts(
+		fmt.Sprintf("parameter %s not found, env var %s is not set", path, name),
+		z.WithType(ParamNotFoundError),
+	)
+}
+
+// Set sets the value for the path in the overlay.
+func (p *EnvParameterProvider) Set(path string, value interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.overlay[path] = value
+
+	return nil
+}
+
+// EnvName returns the name of the env var the path maps to.
+func (p *EnvParameterProvider) EnvName(path string) string {
+	segments := strings.Split(path, ".")
+	if p.prefix != "" {
+		segments = append([]string{p.prefix}, segments...)
+	}
+
+	return p.keyTransform(strings.Join(segments, p.separator))
+}
+
+func defaultEnvKeyTransform(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, key)
+}
@@ -0,0 +1,77 @@
+package di_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestEnvParameterProvider_Get(t *testing.T) {
+	t.Setenv("APP_DB_POOL_SIZE", "10")
+	t.Setenv("APP_DB_HOST_NAME", "localhost")
+
+	pp := di.NewEnvParameterProvider(di.WithEnvPrefix("app"))
+
+	v, err := pp.Get("db.pool.size")
+	assert.NoError(t, err)
+	assert.Equal(t, "10", v)
+
+	v, err = pp.Get("db.host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+
+	v, err = pp.Get("db.user")
+	assert.Nil(t, v)
+	assertErrorType(t, err, di.ParamNotFoundError)
+	assert.Contains(t, err.Error(), "APP_DB_USER")
+}
+
+func TestEnvParameterProvider_Set(t *testing.T) {
+	t.Setenv("DB_HOST", "localhost")
+
+	pp := di.NewEnvParameterProvider()
+
+	assert.NoError(t, pp.Set("db.host", "db.example.com"))
+	assert.NoError(t, pp.Set("db.port", 5432))
+
+	v, err := pp.Get("db.host")
+	assert.NoError(t, err)
+	assert.Equal(t, "db.example.com", v)
+
+	v, err = pp.Get("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, v)
+}
+
+func TestEnvParameterProvider_EnvName(t *testing.T) {
+	assert.Equal(t, "DB_POOL_SIZE", di.NewEnvParameterProvider().EnvName("db.pool.size"))
+	assert.Equal(t, "APP__DB__POOL_SIZE", di.NewEnvParameterProvider(
+		di.WithEnvPrefix("APP"),
+		di.WithEnvSeparator("__"),
+	).EnvName("db.pool_size"))
+	assert.Equal(t, "app.db.pool.size", di.NewEnvParameterProvider(
+		di.WithEnvPrefix("APP"),
+		di.WithEnvSeparator("."),
+		di.WithEnvKeyTransform(strings.ToLower),
+	).EnvName("db.pool.size"))
+}
+
+func TestEnvParameterProvider_Container(t *testing.T) {
+	t.Setenv("APP_GREETING", "hello")
+
+	container := di.NewServiceContainer(
+		di.WithParameterProvider(di.NewEnvParameterProvider(di.WithEnvPrefix("APP"))),
+	)
+	container.Register(
+		di.NewServiceDef(di.StringRef("greeting")).
+			Provider(func(greeting string) string {
+				return greeting
+			}).
+			Args(di.ParamArg("greeting")),
+	)
+
+	greeting, err := di.Get[string](container, di.StringRef("greeting"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", greeting)
+}
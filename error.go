@@ -15,4 +15,23 @@ const (
 	CallableArgCountMismatchError
 	CallableArgTypeMismatchError
 	ParamProviderNotDefinedError
+	ContainerCloseError
+	ServiceDisposeError
+	CircularDependencyError
+	ServiceTypeMismatchError
+	AutowireNoCandidateError
+	AutowireAmbiguousError
+	DefinitionLoadError
+	ProviderNotRegisteredError
+	ServiceScopeError
+	ContainerValidationError
+	ParamNotFoundError
+	ParamLoadError
+	ParamConversionError
+	ParamInterpolationError
+	ParamProviderNotWatchableError
+	StructInjectionError
+	ServiceStartError
+	ServiceStopError
+	ServiceRunError
 )
@@ -1,9 +1,71 @@
 package di
 
+import (
+	"fmt"
+	"time"
+)
+
 //go:generate stringer -type=EventTopic -trimprefix=EventTopic -output=zz_gen_eventtopic_string.go -linecomment
 
 type EventTopic int
 
 const (
-	EventTopicDIReady EventTopic = iota // di:ready
+	EventTopicDIReady            EventTopic = iota // di:ready
+	EventTopicServiceBuilding                      // di:service:building
+	EventTopicServiceBuilt                         // di:service:built
+	EventTopicServiceBuildFailed                   // di:service:build_failed
+	EventTopicServiceRequested                     // di:service:requested
+	EventTopicContainerClosing                     // di:container:closing
+	EventTopicContainerClosed                      // di:container:closed
+	EventTopicParamsChanged                        // di:params:changed
 )
+
+// Service events are published asynchronously, so requesting and building services never waits for subscribers.
+// Because of that, subscribers may receive them in a different order than they were published.
+// Container events are published synchronously like EventTopicDIReady.
+
+// ServiceBuildingEvent is published on EventTopicServiceBuilding before the provider of a service is called.
+type ServiceBuildingEvent struct {
+	Ref fmt.Stringer
+}
+
+// ServiceBuiltEvent is published on EventTopicServiceBuilt after a service was built.
+type ServiceBuiltEvent struct {
+	Ref      fmt.Stringer
+	Duration time.Duration
+	Instance interface{}
+}
+
+// ServiceBuildFailedEvent is published on EventTopicServiceBuildFailed if building a service failed.
+type ServiceBuildFailedEvent struct {
+	Ref      fmt.Stringer
+	Duration time.Duration
+	Err      error
+}
+
+// ServiceRequestedEvent is published on EventTopicServiceRequested each time a service is requested via Get or
+// MustGet. Requests of the container itself, e.g. to build services or to inject them as args, are not published.
+type ServiceRequestedEvent struct {
+	Ref fmt.Stringer
+}
+
+// ContainerClosingEvent is published on EventTopicContainerClosing before services are disposed.
+type ContainerClosingEvent struct {
+	Container *Container
+}
+
+// ContainerClosedEvent is published on EventTopicContainerClosed after all services were disposed.
+// Err contains the disposal errors, if any.
+type ContainerClosedEvent struct {
+	Container *Container
+	Err       error
+}
+
+// ParamsChangedEvent is published on EventTopicParamsChanged after services were updated due to parameter changes.
+// Err contains the errors of services that could not be updated, if any.
+type ParamsChangedEvent struct {
+	Changes  []ParamChange
+	Rebuilt  []fmt.Stringer
+	Notified []fmt.Stringer
+	Err      error
+}
@@ -0,0 +1,79 @@
+package di
+
+import (
+	"github.com/dtomasi/go-event-bus/v3"
+	"sync"
+)
+
+// eventPublisher serializes publishing to the eventbus, which does not support concurrent publishing.
+// Events are queued and published in order by a single goroutine that runs as long as there are queued events.
+// Callers never hold a lock while subscribers are running, so subscribers can safely use the container.
+type eventPublisher struct {
+	mu      sync.Mutex
+	bus     *eventbus.EventBus
+	queue   []*queuedEvent
+	running bool
+}
+
+// queuedEvent is an event waiting to be published. done is nil for events that are published asynchronously.
+type queuedEvent struct {
+	topic EventTopic
+	data  interface{}
+	done  chan struct{}
+}
+
+func newEventPublisher(bus *eventbus.EventBus) *eventPublisher {
+	return &eventPublisher{ //nolint:exhaustivestruct
+		bus: bus,
+	}
+}
+
+// publish publishes data to the eventbus and waits for all subscribers to finish.
+func (p *eventPublisher) publish(topic EventTopic, data interface{}) {
+	evt := &queuedEvent{topic: topic, data: data, done: make(chan struct{})}
+	p.enqueue(evt)
+	<-evt.done
+}
+
+// publishAsync publishes data to the eventbus without waiting for subscribers.
+func (p *eventPublisher) publishAsync(topic EventTopic, data interface{}) {
+	p.enqueue(&queuedEvent{topic: topic, data: data, done: nil})
+}
+
+func (p *eventPublisher) enqueue(evt *queuedEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.queue = append(p.queue, evt)
+
+	if !p.running {
+		p.running = true
+
+		go p.run()
+	}
+}
+
+func (p *eventPublisher) run() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.running = false
+			p.mu.Unlock()
+
+			return
+		}
+
+		evt := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+
+		if evt.done == nil {
+			p.bus.PublishAsync(evt.topic.String(), evt.data)
+
+			continue
+		}
+
+		p.bus.Publish(evt.topic.String(), evt.data)
+		close(evt.done)
+	}
+}
@@ -0,0 +1,173 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/dtomasi/go-event-bus/v3"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder records all events published to the di topics.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events map[string][]interface{}
+}
+
+func recordEvents(eb *eventbus.EventBus) *eventRecorder {
+	r := &eventRecorder{events: map[string][]interface{}{}} //nolint:exhaustivestruct
+	ch := eb.Subscribe("di:*")
+
+	go func() {
+		for evt := range ch {
+			r.mu.Lock()
+			r.events[evt.Topic] = append(r.events[evt.Topic], evt.Data)
+			r.mu.Unlock()
+			evt.Done()
+		}
+	}()
+
+	return r
+}
+
+// wait waits until at least count events were recorded for the given topic.
+func (r *eventRecorder) wait(t *testing.T, topic di.EventTopic, count int) []interface{} {
+	t.Helper()
+
+	var events []interface{}
+
+	assert.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		events = append([]interface{}{}, r.events[topic.String()]...)
+
+		return len(events) >= count
+	}, time.Second, time.Millisecond)
+
+	return events
+}
+
+func TestEventTopic_String(t *testing.T) {
+	assert.Equal(t, "di:ready", di.EventTopicDIReady.String())
+	assert.Equal(t, "di:service:building", di.EventTopicServiceBuilding.String())
+	assert.Equal(t, "di:service:built", di.EventTopicServiceBuilt.String())
+	assert.Equal(t, "di:service:build_failed", di.EventTopicServiceBuildFailed.String())
+	assert.Equal(t, "di:service:requested", di.EventTopicServiceRequested.String())
+	assert.Equal(t, "di:container:closing", di.EventTopicContainerClosing.String())
+	assert.Equal(t, "di:container:closed", di.EventTopicContainerClosed.String())
+}
+
+func TestContainer_ServiceEvents(t *testing.T) {
+	eb := eventbus.NewEventBus()
+	recorder := recordEvents(eb)
+
+	container := di.NewServiceContainer(di.WithEventBus(eb))
+	container.Register(
+		di.NewServiceDef(di.StringRef("foo")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "foo"} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("broken")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(func() (*closeRecorder, error) {
+				return nil, errors.New("broken") //nolint:goerr113
+			}),
+	)
+
+	assert.NoError(t, container.Build())
+
+	built := recorder.wait(t, di.EventTopicServiceBuilt, 1)
+	if assert.Len(t, built, 1) {
+		evt, ok := built[0].(di.ServiceBuiltEvent)
+		assert.True(t, ok)
+		assert.Equal(t, "foo", evt.Ref.String())
+		assert.Equal(t, "foo", evt.Instance.(*closeRecorder).Name())
+		assert.GreaterOrEqual(t, evt.Duration, time.Duration(0))
+	}
+
+	_, err := container.Get(di.StringRef("broken"))
+	assert.Error(t, err)
+
+	failed := recorder.wait(t, di.EventTopicServiceBuildFailed, 1)
+	if assert.Len(t, failed, 1) {
+		evt, ok := failed[0].(di.ServiceBuildFailedEvent)
+		assert.True(t, ok)
+		assert.Equal(t, "broken", evt.Ref.String())
+		assertErrorType(t, evt.Err, di.ServiceBuildError)
+	}
+
+	assert.Len(t, recorder.wait(t, di.EventTopicServiceBuilding, 2), 2)
+	assert.Equal(t, []interface{}{di.ServiceRequestedEvent{Ref: di.StringRef("broken")}},
+		recorder.wait(t, di.EventTopicServiceRequested, 1))
+	assert.Len(t, recorder.wait(t, di.EventTopicDIReady, 1), 1)
+}
+
+func TestContainer_ServiceRequestedEvents(t *testing.T) {
+	eb := eventbus.NewEventBus()
+	recorder := recordEvents(eb)
+
+	container := di.NewServiceContainer(di.WithEventBus(eb))
+	container.Register(
+		di.NewServiceDef(di.StringRef("foo")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "foo"} //nolint:exhaustivestruct
+			}).
+			Tags(di.StringRef("recorder")),
+		di.NewServiceDef(di.StringRef("bar")).
+			Provider(func(foo *closeRecorder, tagged []interface{}) *closeRecorder {
+				return &closeRecorder{name: "bar"} //nolint:exhaustivestruct
+			}).
+			Args(di.ServiceArg(di.StringRef("foo")), di.ServicesByTagsArg([]fmt.Stringer{di.StringRef("recorder")})),
+	)
+
+	// requests of the container itself are not published.
+	assert.NoError(t, container.Build())
+
+	_, err := container.FindByTags([]fmt.Stringer{di.StringRef("recorder")})
+	assert.NoError(t, err)
+
+	_, err = di.FindByTags[*closeRecorder](container, []fmt.Stringer{di.StringRef("recorder")})
+	assert.NoError(t, err)
+
+	container.MustGet(di.StringRef("bar"))
+
+	// events are published in order, so all events before the requested event of bar were recorded as well.
+	assert.Equal(t, []interface{}{di.ServiceRequestedEvent{Ref: di.StringRef("bar")}},
+		recorder.wait(t, di.EventTopicServiceRequested, 1))
+}
+
+func TestContainer_CloseEvents(t *testing.T) {
+	eb := eventbus.NewEventBus()
+	recorder := recordEvents(eb)
+
+	var disposed []string
+
+	container := di.NewServiceContainer(di.WithEventBus(eb))
+	container.Register(
+		di.NewServiceDef(di.StringRef("foo")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "foo", closed: &disposed, err: errors.New("foo")} //nolint:goerr113
+			}),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.Error(t, container.Close(context.Background()))
+
+	closing := recorder.wait(t, di.EventTopicContainerClosing, 1)
+	if assert.Len(t, closing, 1) {
+		assert.Equal(t, di.ContainerClosingEvent{Container: container}, closing[0])
+	}
+
+	closed := recorder.wait(t, di.EventTopicContainerClosed, 1)
+	if assert.Len(t, closed, 1) {
+		evt, ok := closed[0].(di.ContainerClosedEvent)
+		assert.True(t, ok)
+		assert.Equal(t, container, evt.Container)
+		assertErrorType(t, evt.Err, di.ContainerCloseError)
+	}
+}
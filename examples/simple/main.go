@@ -6,8 +6,6 @@ import (
 	"github.com/dtomasi/di"
 	"github.com/dtomasi/di/examples/simple/greeter"
 	"github.com/go-logr/logr/funcr"
-	"os"
-	"os/signal"
 	"time"
 )
 
@@ -41,51 +39,53 @@ func (p *SalutationParameterProvider) Set(key string, value interface{}) error {
 	return nil
 }
 
+// DaytimeGreeting greets depending on the daytime.
+// It implements di.Runnable, so di.Run calls it once the container is built.
+type DaytimeGreeting struct {
+	c *di.Container
+}
+
+func NewDaytimeGreeting(c *di.Container) *DaytimeGreeting {
+	return &DaytimeGreeting{c: c}
+}
+
+func (g *DaytimeGreeting) Run(_ context.Context) error {
+	switch hour := time.Now().Hour(); {
+	case hour < 12: //nolint:gomnd
+		di.MustGet[*greeter.Greeter](g.c, greeter.ServiceGreeterMorning).Greet("John")
+	case hour < 17: //nolint:gomnd
+		di.MustGet[*greeter.Greeter](g.c, greeter.ServiceGreeterAfternoon).Greet("John")
+	default:
+		di.MustGet[*greeter.Greeter](g.c, greeter.ServiceGreeterEvening).Greet("John")
+	}
+
+	return nil
+}
+
 /*
 Steps:
-- create a context to provide it to di
 - initialize a new di container
-	- pass the context
 	- add a logr interface logger for debugging
 	- add our salutation prarameter provider
 - register our services
-- build the container
-- request services and print greeting depending on daytime
+- run the container, which builds it, runs the greeting and shuts down once the greeting returned
+  or on SIGINT and SIGTERM
 
 example output can be found in output.txt.
 */
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, os.Interrupt)
-
-	defer func() {
-		signal.Stop(ch)
-		cancel()
-	}()
-
-	go func() {
-		select {
-		case <-ch:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
+	logger := funcr.New(
+		func(pfx, args string) { fmt.Println(pfx, args) }, //nolint:forbidigo
+		funcr.Options{
+			LogCaller:    funcr.All,
+			LogTimestamp: true,
+			Verbosity:    6, //nolint:gomnd
+		})
 
 	// Create container
 	c := di.NewServiceContainer(
-		// Pass the context
-		di.WithContext(ctx),
 		// Add a debug logger
-		di.WithLogrImpl(funcr.New(
-			func(pfx, args string) { fmt.Println(pfx, args) }, //nolint:forbidigo
-			funcr.Options{
-				LogCaller:    funcr.All,
-				LogTimestamp: true,
-				Verbosity:    6, //nolint:gomnd
-			}),
-		),
+		di.WithLogrImpl(logger),
 		// Pass our parameter provider
 		di.WithParameterProvider(NewMyParameterProvider()),
 	)
@@ -100,12 +100,14 @@ func main() {
 			Provider(greeter.NewGreeter).
 			Args(
 				di.ContextArg(),
+				di.InterfaceArg(logger),
 				di.ParamArg("morning"),
 			),
 		di.NewServiceDef(greeter.ServiceGreeterAfternoon).
 			Provider(greeter.NewGreeter).
 			Args(
 				di.ContextArg(),
+				di.InterfaceArg(logger),
 				di.ParamArg("afternoon"),
 			),
 		di.NewServiceDef(greeter.ServiceGreeterEvening).
@@ -116,21 +118,16 @@ func main() {
 			Provider(greeter.NewGreeter).
 			Args(
 				di.ContextArg(),
+				di.InterfaceArg(logger),
 				di.ParamArg("evening"),
 			),
+		di.NewServiceDef(di.StringRef("greeting")).
+			Provider(NewDaytimeGreeting).
+			Args(di.ContainerArg()),
 	)
 
-	// build container
-	if err := c.Build(); err != nil {
+	// build the container and run until the greeting returned
+	if err := di.Run(context.Background(), c, di.WithShutdownTimeout(5*time.Second)); err != nil { //nolint:gomnd
 		panic(err)
 	}
-
-	switch hour := time.Now().Hour(); {
-	case hour < 12: //nolint:gomnd
-		c.MustGet(greeter.ServiceGreeterMorning).(*greeter.Greeter).Greet("John")
-	case hour < 17: //nolint:gomnd
-		c.MustGet(greeter.ServiceGreeterAfternoon).(*greeter.Greeter).Greet("John")
-	default:
-		c.MustGet(greeter.ServiceGreeterEvening).(*greeter.Greeter).Greet("John")
-	}
 }
@@ -0,0 +1,367 @@
+package di
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileDecoder decodes the content of a parameter file into v.
+// json.Unmarshal and yaml.Unmarshal are valid FileDecoders.
+type FileDecoder func(data []byte, v interface{}) error
+
+// FileParameterProvider is a ParameterProvider that reads parameters from a YAML or JSON file.
+// A dotted parameter path like servers.0.host is resolved by walking maps by key and slices by index.
+// Further formats like TOML can be added with WithFileDecoder.
+// The provider implements WatchableParameterProvider by polling the file for changes.
+type FileParameterProvider struct {
+	path         string
+	format       string
+	decoders     map[string]FileDecoder
+	pollInterval time.Duration
+
+	// mu guards data and loaded
+	mu   sync.RWMutex
+	data interface{}
+	// loaded is the state of the file when it was loaded
+	loaded os.FileInfo
+}
+
+// FileParameterProviderOption defines an option for the FileParameterProvider.
+type FileParameterProviderOption func(p *FileParameterProvider)
+
+// WithFileFormat sets the format of the file, e.g. "yaml". By default, the format is the file extension.
+func WithFileFormat(format string) FileParameterProviderOption {
+	return func(p *FileParameterProvider) {
+		p.format = format
+	}
+}
+
+// WithFileDecoder registers a decoder for files of given format.
+func WithFileDecoder(format string, decoder FileDecoder) FileParameterProviderOption {
+	return func(p *FileParameterProvider) {
+		p.decoders[format] = decoder
+	}
+}
+
+// WithFilePollInterval sets the interval the file is checked for changes with while it is watched.
+// Defaults to one second.
+func WithFilePollInterval(interval time.Duration) FileParameterProviderOption {
+	return func(p *FileParameterProvider) {
+		p.pollInterval = interval
+	}
+}
+
+// NewFileParameterProvider returns a new FileParameterProvider with the parameters loaded from given file.
+func NewFileParameterProvider(path string, opts ...FileParameterProviderOption) (*FileParameterProvider, error) {
+	p := &FileParameterProvider{
+		path:   path,
+		format: strings.TrimPrefix(filepath.Ext(path), "."),
+		decoders: map[string]FileDecoder{
+			"json": json.Unmarshal,
+			"yaml": yaml.Unmarshal,
+			"yml":  yaml.Unmarshal,
+		},
+		pollInterval: time.Second,
+		mu:           sync.RWMutex{},
+		data:         nil,
+		loaded:       nil,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.Load(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Load reads the file again and replaces all parameters, including the ones that were set via Set.
+func (p *FileParameterProvider) Load() (err error) {
+	defer z.WrapPtrWithOpts(&err,
+		fmt.Sprintf("could not load parameters from %s", p.path),
+		z.WithType(ParamLoadError),
+	)
+
+	decoder, ok := p.decoders[p.format]
+	if !ok {
+		return z.Newf("unsupported file format %q", p.format)
+	}
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+	if err = decoder(content, &data); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.data = data
+	p.loaded = info
+
+	return nil
+}
+
+// Watch checks the file for changes in the poll interval until ctx is done.
+// If the modification time or the size of the file changed, the file is loaded again and a ParamChange is sent
+// for each value that differs. If the file cannot be loaded, the current parameters are kept.
+func (p *FileParameterProvider) Watch(ctx context.Context) <-chan ParamChange {
+	changes := make(chan ParamChange)
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(p.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := os.Stat(p.path)
+
+			p.mu.RLock()
+			old, loaded := p.data, p.loaded
+			p.mu.RUnlock()
+
+			if err != nil || sameFileState(loaded, current) {
+				continue
+			}
+
+			if err = p.Load(); err != nil {
+				continue
+			}
+
+			p.mu.RLock()
+			diff := diffParams("", old, p.data)
+			p.mu.RUnlock()
+
+			for _, change := range diff {
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// Get returns the value at given path.
+// If the path does not exist, a ParamNotFoundError is returned that names the segment that failed.
+func (p *FileParameterProvider) Get(path string) (interface{}, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	value := p.data
+
+	for _, segment := range strings.Split(path, ".") {
+		next, reason := lookupSegment(value, segment)
+		if reason != "" {
+			return nil, z.NewWithOpts(
+				fmt.Sprintf("parameter %s not found at segment %q: %s", path, segment, reason),
+				z.WithType(ParamNotFoundError),
+			)
+		}
+
+		value = next
+	}
+
+	return value, nil
+}
+
+// Set sets the value at given path. Missing maps along the path are created.
+// Slice elements can be replaced, but slices are not extended.
+// Values set are kept until the file is loaded again.
+func (p *FileParameterProvider) Set(path string, value interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := setPath(p.data, strings.Split(path, "."), value)
+	if err != nil {
+		return z.WrapWithOpts(err, fmt.Sprintf("could not set parameter %s", path), z.WithType(ParamNotFoundError))
+	}
+
+	p.data = data
+
+	return nil
+}
+
+// lookupSegment returns the child of value at segment. If there is none, the reason is returned.
+func lookupSegment(value interface{}, segment string) (interface{}, string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[segment]
+		if !ok {
+			return nil, "key does not exist"
+		}
+
+		return child, ""
+	case map[interface{}]interface{}:
+		for key, child := range v {
+			if fmt.Sprint(key) == segment {
+				return child, ""
+			}
+		}
+
+		return nil, "key does not exist"
+	case []interface{}:
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, "not a valid slice index"
+		}
+
+		if index < 0 || index >= len(v) {
+			return nil, fmt.Sprintf("index out of range with length %d", len(v))
+		}
+
+		return v[index], ""
+	default:
+		return nil, fmt.Sprintf("cannot look up a key in a value of type %T", value)
+	}
+}
+
+// setPath sets value at the segments below data and returns the updated data.
+// Maps and slices along the path are copied instead of modified, so values returned by Get before stay unchanged.
+func setPath(data interface{}, segments []string, value interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	segment := segments[0]
+
+	switch v := data.(type) {
+	case nil:
+		child, err := setPath(nil, segments[1:], value)
+
+		return map[string]interface{}{segment: child}, err
+	case map[string]interface{}:
+		child, err := setPath(v[segment], segments[1:], value)
+		if err != nil {
+			return v, err
+		}
+
+		updated := make(map[string]interface{}, len(v)+1)
+		for key, existing := range v {
+			updated[key] = existing
+		}
+
+		updated[segment] = child
+
+		return updated, nil
+	case map[interface{}]interface{}:
+		var key interface{} = segment
+
+		for existing := range v {
+			if fmt.Sprint(existing) == segment {
+				key = existing
+
+				break
+			}
+		}
+
+		child, err := setPath(v[key], segments[1:], value)
+		if err != nil {
+			return v, err
+		}
+
+		updated := make(map[interface{}]interface{}, len(v)+1)
+		for existing, existingValue := range v {
+			updated[existing] = existingValue
+		}
+
+		updated[key] = child
+
+		return updated, nil
+	case []interface{}:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(v) {
+			return v, z.Newf("segment %q is not a valid index of a slice with length %d", segment, len(v))
+		}
+
+		child, err := setPath(v[index], segments[1:], value)
+		if err != nil {
+			return v, err
+		}
+
+		updated := append([]interface{}{}, v...)
+		updated[index] = child
+
+		return updated, nil
+	default:
+		return data, z.Newf("cannot set key %q in a value of type %T", segment, data)
+	}
+}
+
+func sameFileState(a os.FileInfo, b os.FileInfo) bool {
+	return a != nil && b != nil && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// diffParams returns a ParamChange for each value below path that differs between old and new.
+func diffParams(path string, oldValue interface{}, newValue interface{}) (changes []ParamChange) {
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+
+		for key := range newMap {
+			if _, ok := oldMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			changes = append(changes, diffParams(joinParamPath(path, key), oldMap[key], newMap[key])...)
+		}
+
+		return changes
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		changes = append(changes, ParamChange{Key: path, OldValue: oldValue, NewValue: newValue})
+	}
+
+	return changes
+}
+
+func joinParamPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,250 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const parametersYAML = `
+db:
+  host: localhost
+  port: 5432
+servers:
+  - host: a.example.com
+  - host: b.example.com
+`
+
+const parametersJSON = `{
+  "db": {"host": "localhost", "port": 5432},
+  "servers": [{"host": "a.example.com"}, {"host": "b.example.com"}]
+}`
+
+func writeParameterFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestFileParameterProvider_Get(t *testing.T) {
+	files := map[string]string{
+		"parameters.yaml": parametersYAML,
+		"parameters.json": parametersJSON,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			pp, err := di.NewFileParameterProvider(writeParameterFile(t, name, content))
+			assert.NoError(t, err)
+
+			v, err := pp.Get("db.host")
+			assert.NoError(t, err)
+			assert.Equal(t, "localhost", v)
+
+			v, err = pp.Get("db.port")
+			assert.NoError(t, err)
+			assert.EqualValues(t, 5432, v)
+
+			v, err = pp.Get("servers.1.host")
+			assert.NoError(t, err)
+			assert.Equal(t, "b.example.com", v)
+		})
+	}
+}
+
+func TestFileParameterProvider_Get_NotFound(t *testing.T) {
+	pp, err := di.NewFileParameterProvider(writeParameterFile(t, "parameters.yaml", parametersYAML))
+	assert.NoError(t, err)
+
+	tests := map[string]string{
+		"db.user":            `parameter db.user not found at segment "user": key does not exist`,
+		"servers.2.host":     `parameter servers.2.host not found at segment "2": index out of range with length 2`,
+		"servers.first.host": `parameter servers.first.host not found at segment "first": not a valid slice index`,
+		"db.host.name": `parameter db.host.name not found at segment "name": ` +
+			`cannot look up a key in a value of type string`,
+	}
+
+	for path, msg := range tests {
+		v, err := pp.Get(path)
+		assert.Nil(t, v)
+		assertErrorType(t, err, di.ParamNotFoundError)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestFileParameterProvider_Set(t *testing.T) {
+	pp, err := di.NewFileParameterProvider(writeParameterFile(t, "parameters.yaml", parametersYAML))
+	assert.NoError(t, err)
+
+	assert.NoError(t, pp.Set("db.user", "admin"))
+	assert.NoError(t, pp.Set("cache.ttl", "1m"))
+	assert.NoError(t, pp.Set("servers.0.host", "c.example.com"))
+	assert.Error(t, pp.Set("servers.2.host", "d.example.com"))
+	assert.Error(t, pp.Set("db.host.name", "foo"))
+
+	for path, expected := range map[string]string{
+		"db.user":        "admin",
+		"cache.ttl":      "1m",
+		"servers.0.host": "c.example.com",
+		"db.host":        "localhost",
+	} {
+		v, err := pp.Get(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+}
+
+func TestFileParameterProvider_Set_KeepsReturnedValues(t *testing.T) {
+	pp, err := di.NewFileParameterProvider(writeParameterFile(t, "parameters.yaml", parametersYAML))
+	assert.NoError(t, err)
+
+	db, err := pp.Get("db")
+	assert.NoError(t, err)
+
+	servers, err := pp.Get("servers")
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, pp.Set("db.user", fmt.Sprintf("user%d", i)))
+			assert.NoError(t, pp.Set("servers.0.host", fmt.Sprintf("host%d.example.com", i)))
+		}
+	}()
+
+	// reading the returned values while Set is called must not race
+	for i := 0; i < 100; i++ {
+		_ = fmt.Sprint(db, servers)
+	}
+
+	<-done
+
+	assert.NotContains(t, db, "user")
+	assert.Equal(t, "a.example.com", servers.([]interface{})[0].(map[string]interface{})["host"]) //nolint:forcetypeassert
+
+	v, err := pp.Get("db.user")
+	assert.NoError(t, err)
+	assert.Equal(t, "user99", v)
+}
+
+func TestFileParameterProvider_Set_InterfaceKeys(t *testing.T) {
+	decoder := func(data []byte, v interface{}) error {
+		*v.(*interface{}) = map[interface{}]interface{}{ //nolint:forcetypeassert
+			"db": map[interface{}]interface{}{"port": 5432, 1: "one"},
+		}
+
+		return nil
+	}
+
+	pp, err := di.NewFileParameterProvider(
+		writeParameterFile(t, "parameters.conf", ""),
+		di.WithFileDecoder("conf", decoder),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, pp.Set("db.port", 5433))
+	assert.NoError(t, pp.Set("db.1", "uno"))
+	assert.NoError(t, pp.Set("db.host", "localhost"))
+
+	for path, expected := range map[string]interface{}{
+		"db.port": 5433,
+		"db.1":    "uno",
+		"db.host": "localhost",
+	} {
+		v, err := pp.Get(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+}
+
+func TestFileParameterProvider_Load_Error(t *testing.T) {
+	_, err := di.NewFileParameterProvider(filepath.Join(t.TempDir(), "missing.yaml"))
+	assertErrorType(t, err, di.ParamLoadError)
+
+	_, err = di.NewFileParameterProvider(writeParameterFile(t, "parameters.toml", "[db]"))
+	assertErrorType(t, err, di.ParamLoadError)
+	assert.Contains(t, err.Error(), `unsupported file format "toml"`)
+
+	_, err = di.NewFileParameterProvider(writeParameterFile(t, "parameters.json", "{"))
+	assertErrorType(t, err, di.ParamLoadError)
+}
+
+func TestFileParameterProvider_WithFileDecoder(t *testing.T) {
+	decoder := func(data []byte, v interface{}) error {
+		key, value, ok := strings.Cut(strings.TrimSpace(string(data)), "=")
+		if !ok {
+			return errors.New("invalid line") //nolint:goerr113
+		}
+
+		*v.(*interface{}) = map[string]interface{}{key: value} //nolint:forcetypeassert
+
+		return nil
+	}
+
+	pp, err := di.NewFileParameterProvider(
+		writeParameterFile(t, "parameters.conf", "greeting=hello"),
+		di.WithFileFormat("ini"),
+		di.WithFileDecoder("ini", decoder),
+	)
+	assert.NoError(t, err)
+
+	v, err := pp.Get("greeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", v)
+}
+
+func TestFileParameterProvider_Watch(t *testing.T) {
+	path := writeParameterFile(t, "parameters.yaml", "log:\n  level: info\nrate: 10\n")
+
+	pp, err := di.NewFileParameterProvider(path, di.WithFilePollInterval(10*time.Millisecond))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := pp.Watch(ctx)
+
+	// make sure the modification time differs on file systems with a coarse resolution
+	modTime := time.Now().Add(time.Second)
+
+	assert.NoError(t, os.WriteFile(path, []byte("log:\n  level: debug\nrate: 10\nburst: 5\n"), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	var received []di.ParamChange
+
+	for len(received) < 2 {
+		select {
+		case change := <-changes:
+			received = append(received, change)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for parameter changes")
+		}
+	}
+
+	assert.Equal(t, []di.ParamChange{
+		{Key: "burst", OldValue: nil, NewValue: 5},
+		{Key: "log.level", OldValue: "info", NewValue: "debug"},
+	}, received)
+
+	v, err := pp.Get("log.level")
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", v)
+
+	cancel()
+
+	_, ok := <-changes
+	assert.False(t, ok)
+}
@@ -0,0 +1,267 @@
+package di
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Node kinds of the dependency graph.
+const (
+	GraphNodeService = "service"
+	GraphNodeParam   = "param"
+	GraphNodeMissing = "missing"
+	GraphNodeAlias   = "alias"
+)
+
+// Edge kinds of the dependency graph.
+const (
+	GraphEdgeService    = "service"
+	GraphEdgeMethodCall = "method_call"
+	GraphEdgeTagged     = "tagged"
+	GraphEdgeParam      = "param"
+	GraphEdgeAlias      = "alias"
+)
+
+// Lifetimes of services in the dependency graph.
+const (
+	LifetimeSingleton = "singleton"
+	LifetimeLazy      = "lazy"
+	LifetimeTransient = "transient"
+	LifetimeScoped    = "scoped"
+)
+
+// graphParamPrefix is used to build node ids of parameters, so they do not collide with service refs.
+const graphParamPrefix = "param:"
+
+// Graph describes how the services of a container are wired.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a service or a parameter within the dependency graph.
+type GraphNode struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"`
+	Lifetime string   `json:"lifetime,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// GraphEdge points from a service to one of its dependencies.
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+}
+
+// graphArg is implemented by arguments that are shown as edges in the dependency graph.
+type graphArg interface {
+	graphEdges(c *Container, from string) []GraphEdge
+}
+
+// Graph returns the dependency graph of all registered services without building any of them.
+// Nodes are sorted by id and edges keep the order of the provider arguments, so the output is stable.
+func (c *Container) Graph() *Graph {
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	defs := c.allServiceDefs()
+	targets := map[string]string{}
+
+	refs := make([]fmt.Stringer, 0, len(defs))
+	for ref := range defs {
+		refs = append(refs, ref)
+	}
+
+	for _, ref := range sortRefs(refs) {
+		def := defs[ref]
+
+		node := GraphNode{
+			ID:       ref.String(),
+			Kind:     GraphNodeService,
+			Lifetime: def.lifetime(),
+			Type:     "",
+			Tags:     refNames(def.tags),
+		}
+
+		if serviceType := c.serviceType(def); serviceType != nil {
+			node.Type = serviceType.String()
+		}
+
+		g.Nodes = append(g.Nodes, node)
+
+		args, _ := c.resolveArgs(def)
+		for _, edge := range argGraphEdges(c, ref.String(), joinArgs(args, callArgs(def), c.decoratorArgs(def))) {
+			targets[edge.To] = edge.Kind
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+
+	aliases := c.allAliases()
+
+	aliasRefs := make([]fmt.Stringer, 0, len(aliases))
+	for alias := range aliases {
+		aliasRefs = append(aliasRefs, alias)
+	}
+
+	for _, alias := range sortRefs(aliasRefs) {
+		g.Nodes = append(g.Nodes, GraphNode{ID: alias.String(), Kind: GraphNodeAlias}) //nolint:exhaustivestruct
+
+		edge := GraphEdge{From: alias.String(), To: aliases[alias].String(), Kind: GraphEdgeAlias} //nolint:exhaustivestruct
+		g.Edges = append(g.Edges, edge)
+		targets[aliases[alias].String()] = GraphEdgeAlias
+	}
+
+	// add nodes for parameters and for services that are referenced but not registered.
+	for id, kind := range targets {
+		if kind == GraphEdgeParam {
+			g.Nodes = append(g.Nodes, GraphNode{ID: id, Kind: GraphNodeParam}) //nolint:exhaustivestruct
+
+			continue
+		}
+
+		if !g.hasNode(id) {
+			g.Nodes = append(g.Nodes, GraphNode{ID: id, Kind: GraphNodeMissing}) //nolint:exhaustivestruct
+		}
+	}
+
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+
+	return g
+}
+
+func (g *Graph) hasNode(id string) bool {
+	for _, node := range g.Nodes {
+		if node.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func argGraphEdges(c *Container, from string, args []ServiceDefArg) []GraphEdge {
+	var edges []GraphEdge
+
+	for _, arg := range args {
+		if a, ok := arg.(graphArg); ok {
+			edges = append(edges, a.graphEdges(c, from)...)
+		}
+	}
+
+	return edges
+}
+
+// JSON renders the graph as indented JSON.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT renders the graph in the Graphviz DOT language.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph di {\n")
+
+	for _, node := range g.Nodes {
+		switch node.Kind {
+		case GraphNodeParam:
+			fmt.Fprintf(&b, "\t%q [label=%q, shape=note];\n", node.ID, node.label())
+
+			continue
+		case GraphNodeMissing:
+			fmt.Fprintf(&b, "\t%q [label=%q, shape=box, style=dashed];\n", node.ID, node.label())
+
+			continue
+		case GraphNodeAlias:
+			fmt.Fprintf(&b, "\t%q [label=%q, shape=ellipse];\n", node.ID, node.label())
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "\t%q [label=%q, shape=box];\n", node.ID, node.label())
+	}
+
+	for _, edge := range g.Edges {
+		if edge.Label != "" {
+			fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", edge.From, edge.To, edge.Label)
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "\t%q -> %q;\n", edge.From, edge.To)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+
+	ids := make(map[string]string, len(g.Nodes))
+
+	b.WriteString("flowchart LR\n")
+
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+
+		switch node.Kind {
+		case GraphNodeParam:
+			fmt.Fprintf(&b, "\t%s[/\"%s\"/]\n", ids[node.ID], mermaidEscape(node.label()))
+
+			continue
+		case GraphNodeMissing:
+			fmt.Fprintf(&b, "\t%s[(\"%s\")]\n", ids[node.ID], mermaidEscape(node.label()))
+
+			continue
+		case GraphNodeAlias:
+			fmt.Fprintf(&b, "\t%s([\"%s\"])\n", ids[node.ID], mermaidEscape(node.label()))
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[node.ID], mermaidEscape(node.label()))
+	}
+
+	for _, edge := range g.Edges {
+		if edge.Label != "" {
+			fmt.Fprintf(&b, "\t%s -- \"%s\" --> %s\n", ids[edge.From], mermaidEscape(edge.Label), ids[edge.To])
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "\t%s --> %s\n", ids[edge.From], ids[edge.To])
+	}
+
+	return b.String()
+}
+
+// label returns the text that is displayed for a node by the renderers.
+func (n GraphNode) label() string {
+	switch n.Kind {
+	case GraphNodeParam:
+		return strings.TrimPrefix(n.ID, graphParamPrefix)
+	case GraphNodeMissing:
+		return n.ID + "\nmissing"
+	case GraphNodeAlias:
+		return n.ID + "\nalias"
+	}
+
+	label := fmt.Sprintf("%s\n%s", n.ID, n.Lifetime)
+	if len(n.Tags) > 0 {
+		label += fmt.Sprintf("\ntags: %s", strings.Join(n.Tags, ", "))
+	}
+
+	return label
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s)
+}
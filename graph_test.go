@@ -0,0 +1,104 @@
+package di_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newGraphTestContainer() *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("TestService1")).
+			Provider(NewTestService1).
+			Args(
+				di.ContextArg(),
+				di.ContainerArg(),
+				di.InterfaceArg(true),
+				di.ParamArg("foo.bar"),
+			).
+			Tags(di.StringRef("foo")),
+		di.NewServiceDef(di.StringRef("TestService2")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(NewTestService2).
+			Args(
+				di.ServiceArg(di.StringRef("TestService1")),
+				di.ServicesByTagsArg([]fmt.Stringer{di.StringRef("foo")}),
+				di.ServiceArg(di.StringRef("missing")),
+				di.ParamArg("foo.bar"),
+				di.ServiceMethodCallArg(di.StringRef("TestService1"), "TestFactoryMethod", di.InterfaceArg("foo")),
+			),
+	)
+
+	return container
+}
+
+func TestContainer_Graph(t *testing.T) {
+	g := newGraphTestContainer().Graph()
+
+	assert.Equal(t, []di.GraphNode{
+		{
+			ID: "TestService1", Kind: di.GraphNodeService, Lifetime: di.LifetimeSingleton,
+			Type: "*di_test.TestService1", Tags: []string{"foo"},
+		},
+		{
+			ID: "TestService2", Kind: di.GraphNodeService, Lifetime: di.LifetimeLazy,
+			Type: "*di_test.TestService2", Tags: []string{},
+		},
+		{ID: "missing", Kind: di.GraphNodeMissing},     //nolint:exhaustivestruct
+		{ID: "param:foo.bar", Kind: di.GraphNodeParam}, //nolint:exhaustivestruct
+	}, g.Nodes)
+
+	assert.Equal(t, []di.GraphEdge{
+		{From: "TestService1", To: "param:foo.bar", Kind: di.GraphEdgeParam},  //nolint:exhaustivestruct
+		{From: "TestService2", To: "TestService1", Kind: di.GraphEdgeService}, //nolint:exhaustivestruct
+		{From: "TestService2", To: "TestService1", Kind: di.GraphEdgeTagged, Label: "tags: foo"},
+		{From: "TestService2", To: "missing", Kind: di.GraphEdgeService},     //nolint:exhaustivestruct
+		{From: "TestService2", To: "param:foo.bar", Kind: di.GraphEdgeParam}, //nolint:exhaustivestruct
+		{From: "TestService2", To: "TestService1", Kind: di.GraphEdgeMethodCall, Label: "TestFactoryMethod()"},
+	}, g.Edges)
+}
+
+func TestGraph_JSON(t *testing.T) {
+	out, err := newGraphTestContainer().Graph().JSON()
+	assert.NoError(t, err)
+
+	var g di.Graph
+	assert.NoError(t, json.Unmarshal(out, &g))
+	assert.Len(t, g.Nodes, 4)
+	assert.Len(t, g.Edges, 6)
+	assert.Contains(t, string(out), `"lifetime": "singleton"`)
+}
+
+func TestGraph_DOT(t *testing.T) {
+	assert.Equal(t, `digraph di {
+	"TestService1" [label="TestService1\nsingleton\ntags: foo", shape=box];
+	"TestService2" [label="TestService2\nlazy", shape=box];
+	"missing" [label="missing\nmissing", shape=box, style=dashed];
+	"param:foo.bar" [label="foo.bar", shape=note];
+	"TestService1" -> "param:foo.bar";
+	"TestService2" -> "TestService1";
+	"TestService2" -> "TestService1" [label="tags: foo"];
+	"TestService2" -> "missing";
+	"TestService2" -> "param:foo.bar";
+	"TestService2" -> "TestService1" [label="TestFactoryMethod()"];
+}
+`, newGraphTestContainer().Graph().DOT())
+}
+
+func TestGraph_Mermaid(t *testing.T) {
+	assert.Equal(t, `flowchart LR
+	n0["TestService1<br/>singleton<br/>tags: foo"]
+	n1["TestService2<br/>lazy"]
+	n2[("missing<br/>missing")]
+	n3[/"foo.bar"/]
+	n0 --> n3
+	n1 --> n0
+	n1 -- "tags: foo" --> n0
+	n1 --> n2
+	n1 --> n3
+	n1 -- "TestFactoryMethod()" --> n0
+`, newGraphTestContainer().Graph().Mermaid())
+}
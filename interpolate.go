@@ -0,0 +1,247 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"strings"
+)
+
+// placeholder is a part of a string that is either a literal or a reference to a parameter.
+type placeholder struct {
+	literal    string
+	key        string
+	def        string
+	hasDefault bool
+}
+
+// interpolator resolves placeholders of the form %key% and ${key} or ${key:default} against a ParameterProvider.
+// If values is true, parameter values that are strings are interpolated as well. keys holds the chain of
+// parameters that are currently resolved to detect circular references.
+type interpolator struct {
+	pp     ParameterProvider
+	values bool
+	keys   []string
+}
+
+// getParam returns the parameter with given path. Placeholders in its value are resolved if enabled by
+// WithParamInterpolation. If hasDefault is true, def is used if the ParameterProvider cannot provide the parameter.
+func (c *Container) getParam(path string, def interface{}, hasDefault bool) (interface{}, error) {
+	in := &interpolator{pp: c.paramProvider, values: c.interpolateParams, keys: nil}
+
+	return in.resolve(path, def, hasDefault)
+}
+
+// interpolate resolves all placeholders in s.
+// If s consists of a single placeholder, the parameter value is returned as is, so it keeps its type.
+func (c *Container) interpolate(s string) (interface{}, error) {
+	in := &interpolator{pp: c.paramProvider, values: c.interpolateParams, keys: nil}
+
+	return in.interpolate(s)
+}
+
+func (in *interpolator) interpolate(s string) (interface{}, error) {
+	parts := parsePlaceholders(s)
+
+	if len(parts) == 1 && parts[0].key != "" {
+		return in.resolvePlaceholder(parts[0])
+	}
+
+	var b strings.Builder
+
+	for _, part := range parts {
+		if part.key == "" {
+			b.WriteString(part.literal)
+
+			continue
+		}
+
+		value, err := in.resolvePlaceholder(part)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprint(&b, value)
+	}
+
+	return b.String(), nil
+}
+
+func (in *interpolator) resolvePlaceholder(p placeholder) (interface{}, error) {
+	// the key may contain placeholders itself, e.g. ${db.${env}.host}
+	key, err := in.interpolate(p.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return in.resolve(fmt.Sprint(key), p.def, p.hasDefault)
+}
+
+func (in *interpolator) resolve(key string, def interface{}, hasDefault bool) (interface{}, error) {
+	for i, k := range in.keys {
+		if k == key {
+			return nil, z.NewWithOpts(
+				fmt.Sprintf("circular parameter reference detected: %s -> %s",
+					strings.Join(in.keys[i:], " -> "), key,
+				),
+				z.WithType(ParamInterpolationError),
+			)
+		}
+	}
+
+	value, err := in.pp.Get(key)
+
+	switch {
+	case err == nil:
+	case hasDefault:
+		value = def
+	case len(in.keys) > 0:
+		return nil, z.Wrapf(err, "parameter %s referenced by %s cannot be resolved", key, in.keys[len(in.keys)-1])
+	default:
+		return nil, err
+	}
+
+	s, ok := value.(string)
+	if !ok || !in.values {
+		return value, nil
+	}
+
+	in.keys = append(in.keys, key)
+	defer func() {
+		in.keys = in.keys[:len(in.keys)-1]
+	}()
+
+	return in.interpolate(s)
+}
+
+// parsePlaceholders splits s into literals and placeholders.
+// %% and $${ are escapes for a literal % and ${. A % that is not followed by a valid key and a closing % is
+// kept as literal, so values like "100%" stay untouched.
+func parsePlaceholders(s string) []placeholder {
+	var (
+		parts   []placeholder
+		literal strings.Builder
+	)
+
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, placeholder{literal: literal.String()}) //nolint:exhaustivestruct
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "%%"):
+			literal.WriteByte('%')
+			i += 2
+		case strings.HasPrefix(s[i:], "$${"):
+			literal.WriteString("${")
+			i += 3
+		case s[i] == '%':
+			end := strings.IndexByte(s[i+1:], '%')
+			if end < 0 || !isParamKey(s[i+1:i+1+end]) {
+				literal.WriteByte('%')
+				i++
+
+				continue
+			}
+
+			flush()
+			parts = append(parts, placeholder{key: s[i+1 : i+1+end]}) //nolint:exhaustivestruct
+			i += end + 2
+		case strings.HasPrefix(s[i:], "${"):
+			end := matchingBrace(s, i+2)
+			if end < 0 {
+				literal.WriteString("${")
+				i += 2
+
+				continue
+			}
+
+			p := parseBracePlaceholder(s[i+2 : end])
+			if p.key == "" {
+				literal.WriteString(s[i : end+1])
+				i = end + 1
+
+				continue
+			}
+
+			flush()
+			parts = append(parts, p)
+			i = end + 1
+		default:
+			literal.WriteByte(s[i])
+			i++
+		}
+	}
+
+	flush()
+
+	return parts
+}
+
+// parseBracePlaceholder parses the content of ${...}. The default value starts after the first ":" that is not
+// part of a nested placeholder.
+func parseBracePlaceholder(content string) placeholder {
+	depth := 0
+
+	for i := 0; i < len(content); i++ {
+		switch {
+		case strings.HasPrefix(content[i:], "${"):
+			depth++
+			i++
+		case content[i] == '}':
+			depth--
+		case content[i] == ':' && depth == 0:
+			return placeholder{key: content[:i], def: content[i+1:], hasDefault: true} //nolint:exhaustivestruct
+		}
+	}
+
+	return placeholder{key: content} //nolint:exhaustivestruct
+}
+
+// matchingBrace returns the index of the "}" that closes the placeholder whose content starts at start.
+func matchingBrace(s string, start int) int {
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}' && depth == 0:
+			return i
+		case s[i] == '}':
+			depth--
+		}
+	}
+
+	return -1
+}
+
+// isParamKey returns true if s is a valid key for a %key% placeholder.
+func isParamKey(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !(r == '.' || r == '_' || r == '-' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// placeholderKeys returns the keys of all placeholders in s that do not contain placeholders themselves.
+func placeholderKeys(s string) (keys []string) {
+	for _, p := range parsePlaceholders(s) {
+		if p.key != "" && !strings.ContainsAny(p.key, "%$") {
+			keys = append(keys, p.key)
+		}
+	}
+
+	return keys
+}
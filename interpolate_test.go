@@ -0,0 +1,114 @@
+package di_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStringArg(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"db.host":         "localhost",
+		"db.port":         5432,
+		"db.user":         "%db.name%_user",
+		"db.name":         "app",
+		"env":             "prod",
+		"prod.db.host":    "db.example.com",
+		"db.dsn":          "postgres://${db.user}@%db.host%:${db.port}/${db.name}",
+		"db.pool.percent": "100%",
+	}, di.WithParamInterpolation())
+
+	tests := map[string]string{
+		"postgres://%db.host%:%db.port%":        "postgres://localhost:5432",
+		"postgres://${db.host}:${db.port}":      "postgres://localhost:5432",
+		"${db.missing:fallback}":                "fallback",
+		"${db.timeout:${db.port}}":              "5432",
+		"${${env}.db.host}":                     "db.example.com",
+		"%db.dsn%":                              "postgres://app_user@localhost:5432/app",
+		"%db.pool.percent% and 50% of %db.host": "100% and 50% of %db.host",
+		"%%db.host%% $${db.host} ${}":           "%db.host% ${db.host} ${}",
+	}
+
+	for template, expected := range tests {
+		t.Run(template, func(t *testing.T) {
+			v, err := getParam(t, c, func(v string) string { return v }, di.StringArg(template))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, v)
+		})
+	}
+}
+
+func TestStringArg_KeepsType(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{"db.port": 5432})
+
+	v, err := getParam(t, c, func(v interface{}) interface{} { return v }, di.StringArg("%db.port%"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, v)
+
+	v, err = getParam(t, c, func(v uint16) uint16 { return v }, di.StringArg("${db.port}"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(5432), v)
+}
+
+func TestStringArg_Errors(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"a":    "%b%",
+		"b":    "${c}",
+		"c":    "prefix-%a%",
+		"self": "${self}",
+		"dsn":  "%db.host%",
+	}, di.WithParamInterpolation())
+
+	_, err := getParam(t, c, func(v string) string { return v }, di.StringArg("%a%"))
+	assertErrorType(t, err, di.ParamInterpolationError)
+	assert.Contains(t, err.Error(), "circular parameter reference detected: a -> b -> c -> a")
+
+	_, err = getParam(t, c, func(v string) string { return v }, di.ParamArg("self"))
+	assertErrorType(t, err, di.ParamInterpolationError)
+	assert.Contains(t, err.Error(), "circular parameter reference detected: self -> self")
+
+	_, err = getParam(t, c, func(v string) string { return v }, di.ParamArg("dsn"))
+	assertErrorType(t, err, di.ParamNotFoundError)
+	assert.Contains(t, err.Error(), "parameter db.host referenced by dsn cannot be resolved")
+}
+
+func TestParamArg_Interpolation(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"db.host": "localhost",
+		"db.port": "5432",
+		"db.addr": "%db.host%:${db.port}",
+	}, di.WithParamInterpolation())
+
+	v, err := getParam(t, c, func(v string) string { return v }, di.ParamArg("db.addr"))
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:5432", v)
+}
+
+func TestParamArg_NoInterpolation(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"db.host":     "localhost",
+		"db.password": "p%db.host%w%%rd${x}",
+	})
+
+	// parameter values are used as they are without WithParamInterpolation
+	v, err := getParam(t, c, func(v string) string { return v }, di.ParamArg("db.password"))
+	assert.NoError(t, err)
+	assert.Equal(t, "p%db.host%w%%rd${x}", v)
+
+	v, err = getParam(t, c, func(v string) string { return v }, di.StringArg("%db.host%:${db.password}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:p%db.host%w%%rd${x}", v)
+}
+
+func TestStringArg_Graph(t *testing.T) {
+	c := di.NewServiceContainer()
+	c.Register(
+		di.NewServiceDef(di.StringRef("db")).
+			Provider(func(dsn string) string { return dsn }).
+			Args(di.StringArg("%db.host%:${db.port:5432}")),
+	)
+
+	edges := c.Graph().Edges
+	assert.Contains(t, edges, di.GraphEdge{From: "db", To: "param:db.host", Kind: di.GraphEdgeParam, Label: ""})
+	assert.Contains(t, edges, di.GraphEdge{From: "db", To: "param:db.port", Kind: di.GraphEdgeParam, Label: ""})
+}
@@ -0,0 +1,192 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di/internal/pkg/utils"
+	z "github.com/dtomasi/zerrors"
+	"github.com/hashicorp/go-multierror"
+	"sync"
+)
+
+// Initializer is implemented by services that need to be initialized after construction.
+// Init is called with the container context after the provider returned, the decorators were applied and all
+// calls were executed. An error fails the build of the service.
+// Like Start and Stop, Init is called on the instance that is returned by Get, which is the decorated instance
+// if decorators are defined.
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Starter is implemented by services that are started once the container is built.
+// Start is called by Container.Build in dependency order, so dependencies are started first. Services that are
+// built afterwards, like lazy and scoped services, are started when they are built. If Start fails, the build of
+// such a service fails.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by services that are stopped when the container is closed.
+// Stop is called by Container.Close in reverse dependency order for all built services, whether they implement
+// Starter or not. Services that were stopped already or failed to start are skipped.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// serviceState is the lifecycle state of a service instance.
+type serviceState int
+
+const (
+	// serviceBuilt is the state of an instance that was neither started nor stopped.
+	serviceBuilt serviceState = iota
+	serviceStarted
+	// serviceStopped is the state of an instance that was stopped or failed to start, so it is not stopped again.
+	serviceStopped
+)
+
+// containerLifecycle records whether Container.Build started the services of a container.
+type containerLifecycle struct {
+	mu      sync.Mutex
+	started bool
+}
+
+func newContainerLifecycle() *containerLifecycle {
+	return &containerLifecycle{mu: sync.Mutex{}, started: false}
+}
+
+func (l *containerLifecycle) isStarted() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.started
+}
+
+func (l *containerLifecycle) setStarted(started bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.started = started
+}
+
+// servicesStarted reports whether Build started the services of the container or of one of its parents, so
+// services that are built now have to be started as well.
+func (c *Container) servicesStarted() bool {
+	for container := c; container != nil; container = container.parent {
+		if container.lifecycle.isStarted() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// initInstance calls Init if the instance implements Initializer.
+func (c *Container) initInstance(instance interface{}) error {
+	initializer, ok := instance.(Initializer)
+	if !ok {
+		return nil
+	}
+
+	if err := initializer.Init(c.ctx); err != nil {
+		return z.Wrapf(err, "could not initialize service")
+	}
+
+	return nil
+}
+
+// startServices starts all built services that are not started yet.
+// If a service fails to start, all services started by this call are stopped again in reverse order.
+func (c *Container) startServices() (err error) {
+	var built []fmt.Stringer
+
+	_ = c.serviceDefs.Range(func(key fmt.Stringer, def *ServiceDef) error {
+		if def.provider != nil && !def.options.alwaysRebuild && def.getInstance() != nil {
+			built = append(built, key)
+		}
+
+		return nil
+	})
+
+	var started []*ServiceDef
+
+	for _, ref := range c.sortByDependencies(built) {
+		def, ok := c.serviceDefs.Load(ref)
+		if !ok || def.getState() == serviceStarted {
+			continue
+		}
+
+		if err = c.startService(def, def.getInstance()); err != nil {
+			break
+		}
+
+		started = append(started, def)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	// roll back the services that were started.
+	for i := len(started) - 1; i >= 0; i-- {
+		if stopErr := c.stopService(c.ctx, started[i]); stopErr != nil {
+			err = multierror.Append(err, stopErr)
+		}
+	}
+
+	return err
+}
+
+// startService starts the instance of the service if it implements Starter.
+func (c *Container) startService(def *ServiceDef, instance interface{}) (err error) {
+	defer z.WrapPtrWithOpts(&err,
+		fmt.Sprintf("error while starting service %s", def.ref),
+		z.WithType(ServiceStartError),
+	)
+
+	starter, ok := instance.(Starter)
+	if !ok {
+		def.setState(serviceStarted)
+
+		return nil
+	}
+
+	c.logger.V(utils.LogLevelDebug).Info("starting service", "name", def.ref.String())
+
+	if err = starter.Start(c.ctx); err != nil {
+		def.setState(serviceStopped)
+
+		return err
+	}
+
+	def.setState(serviceStarted)
+
+	return nil
+}
+
+// stopService stops the instance of the service if it implements Stopper.
+// Services that were stopped already or failed to start are skipped.
+func (c *Container) stopService(ctx context.Context, def *ServiceDef) (err error) {
+	if def.getState() == serviceStopped {
+		return nil
+	}
+
+	defer z.WrapPtrWithOpts(&err,
+		fmt.Sprintf("error while stopping service %s", def.ref),
+		z.WithType(ServiceStopError),
+	)
+
+	def.setState(serviceStopped)
+
+	stopper, ok := def.getInstance().(Stopper)
+	if !ok {
+		return nil
+	}
+
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	c.logger.V(utils.LogLevelDebug).Info("stopping service", "name", def.ref.String())
+
+	return stopper.Stop(ctx)
+}
@@ -0,0 +1,168 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type lifecycleService struct {
+	name     string
+	events   *[]string
+	startErr error
+}
+
+func (s *lifecycleService) Init(_ context.Context) error {
+	*s.events = append(*s.events, "init:"+s.name)
+
+	return nil
+}
+
+func (s *lifecycleService) Start(_ context.Context) error {
+	if s.startErr != nil {
+		return s.startErr
+	}
+
+	*s.events = append(*s.events, "start:"+s.name)
+
+	return nil
+}
+
+func (s *lifecycleService) Stop(_ context.Context) error {
+	*s.events = append(*s.events, "stop:"+s.name)
+
+	return nil
+}
+
+func newLifecycleContainer(events *[]string, startErr error) *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("server")).
+			Provider(func(_ *lifecycleService) *lifecycleService {
+				return &lifecycleService{name: "server", events: events, startErr: startErr}
+			}).
+			Args(di.ServiceArg(di.StringRef("database"))),
+		di.NewServiceDef(di.StringRef("database")).
+			Provider(func() *lifecycleService {
+				return &lifecycleService{name: "database", events: events, startErr: nil}
+			}),
+	)
+
+	return container
+}
+
+func TestContainer_Lifecycle(t *testing.T) {
+	var events []string
+
+	container := newLifecycleContainer(&events, nil)
+
+	assert.NoError(t, container.Build())
+	assert.Equal(t, []string{"init:database", "init:server", "start:database", "start:server"}, events)
+
+	// a second build does not start the services again
+	assert.NoError(t, container.Build())
+	assert.Len(t, events, 4)
+
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"stop:server", "stop:database"}, events[4:])
+}
+
+func TestContainer_Lifecycle_StartError(t *testing.T) {
+	var events []string
+
+	container := newLifecycleContainer(&events, errors.New("port in use"))
+
+	err := container.Build()
+	assertErrorType(t, err, di.ContainerBuildError)
+	assert.Contains(t, err.Error(), "error while starting service server")
+	assert.Contains(t, err.Error(), "port in use")
+	assert.Equal(t, []string{"init:database", "init:server", "start:database", "stop:database"}, events)
+
+	// rolled back services are not stopped again
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Len(t, events, 4)
+}
+
+func TestContainer_Lifecycle_InitError(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("service")).
+			Provider(func() *failingInitializer { return &failingInitializer{} }),
+	)
+
+	_, err := container.Get(di.StringRef("service"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "could not initialize service")
+}
+
+type failingInitializer struct{}
+
+func (f *failingInitializer) Init(_ context.Context) error {
+	return errors.New("init failed")
+}
+
+func TestContainer_Lifecycle_BuiltAfterBuild(t *testing.T) {
+	var events []string
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("lazy")).
+			Opts(di.BuildOnFirstRequest()).
+			Provider(func() *lifecycleService {
+				return &lifecycleService{name: "lazy", events: &events, startErr: nil}
+			}),
+		di.NewServiceDef(di.StringRef("scoped")).
+			Opts(di.Scoped()).
+			Provider(func() *lifecycleService {
+				return &lifecycleService{name: "scoped", events: &events, startErr: nil}
+			}),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.Empty(t, events)
+
+	_, err := container.Get(di.StringRef("lazy"))
+	assert.NoError(t, err)
+
+	scope := container.NewScope(context.Background())
+	_, err = scope.Get(di.StringRef("scoped"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"init:lazy", "start:lazy", "init:scoped", "start:scoped"}, events)
+
+	assert.NoError(t, scope.Close(context.Background()))
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"stop:scoped", "stop:lazy"}, events[4:])
+}
+
+func TestContainer_Lifecycle_WithoutBuild(t *testing.T) {
+	var events []string
+
+	container := newLifecycleContainer(&events, nil)
+
+	// services are only started by Build, but all built services are stopped.
+	_, err := container.Get(di.StringRef("server"))
+	assert.NoError(t, err)
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"init:database", "init:server", "stop:server", "stop:database"}, events)
+}
+
+func TestContainer_Lifecycle_Decorated(t *testing.T) {
+	var events []string
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("service")).
+			Provider(func() *lifecycleService {
+				return &lifecycleService{name: "raw", events: &events, startErr: nil}
+			}),
+	)
+	container.Decorate(di.StringRef("service"), func(_ *lifecycleService) *lifecycleService {
+		return &lifecycleService{name: "decorated", events: &events, startErr: nil}
+	})
+
+	assert.NoError(t, container.Build())
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"init:decorated", "start:decorated", "stop:decorated"}, events)
+}
@@ -37,3 +37,20 @@ func WithEventBus(eb *eventbus.EventBus) Option {
 		c.eventBus = eb
 	}
 }
+
+// WithAutowiring enables autowiring of provider parameters for all service definitions.
+// See Autowire for details.
+func WithAutowiring() Option {
+	return func(c *Container) {
+		c.autowire = true
+	}
+}
+
+// WithParamInterpolation enables placeholders in string parameter values, e.g. a parameter db.dsn with the value
+// "postgres://%db.host%/app". They are resolved for ParamArg values and for parameters referenced by StringArg.
+// Without this option, parameter values are used as they are, so values like passwords may contain % and ${.
+func WithParamInterpolation() Option {
+	return func(c *Container) {
+		c.interpolateParams = true
+	}
+}
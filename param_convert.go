@@ -0,0 +1,313 @@
+package di
+
+import (
+	"encoding"
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// convertingArg is implemented by arguments whose value is converted to the type of the provider parameter.
+type convertingArg interface {
+	convert(value interface{}, targetType reflect.Type) (interface{}, error)
+}
+
+// convertParam converts a parameter value to given type.
+// Strings are parsed into numbers, bools, time.Duration and types implementing encoding.TextUnmarshaler.
+// Numbers are converted into other number types if the value fits. Slices and maps are converted element by
+// element, a string is split at "," into a slice and "key=value" pairs separated by "," into a map.
+func convertParam(value interface{}, targetType reflect.Type) (interface{}, error) {
+	if value == nil || reflect.TypeOf(value).AssignableTo(targetType) {
+		return value, nil
+	}
+
+	converted, err := convertValue(reflect.ValueOf(value), targetType)
+	if err != nil {
+		return nil, z.WrapWithOpts(err,
+			fmt.Sprintf("cannot convert %v (%T) to %s", value, value, targetType),
+			z.WithType(ParamConversionError),
+		)
+	}
+
+	return converted.Interface(), nil
+}
+
+func convertValue(value reflect.Value, targetType reflect.Type) (reflect.Value, error) {
+	// unwrap interface{} values, e.g. elements of []interface{}
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() || (value.Kind() == reflect.Interface && value.IsNil()) {
+		return reflect.Zero(targetType), nil
+	}
+
+	if value.Type().AssignableTo(targetType) {
+		return value, nil
+	}
+
+	if value.Kind() == reflect.String {
+		if converted, ok, err := unmarshalText(value.String(), targetType); ok {
+			return converted, err
+		}
+	}
+
+	if targetType == durationType {
+		return convertDuration(value)
+	}
+
+	switch targetType.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return reflect.ValueOf(fmt.Sprint(value.Interface())).Convert(targetType), nil
+	case reflect.Bool:
+		return convertBool(value, targetType)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return convertNumber(value, targetType)
+	case reflect.Slice:
+		return convertSlice(value, targetType)
+	case reflect.Map:
+		return convertMap(value, targetType)
+	}
+
+	return reflect.Value{}, z.Newf("unsupported conversion from %s", value.Type())
+}
+
+// unmarshalText uses encoding.TextUnmarshaler if it is implemented by the target type or a pointer to it.
+func unmarshalText(text string, targetType reflect.Type) (reflect.Value, bool, error) {
+	switch {
+	case targetType.Kind() == reflect.Ptr && targetType.Implements(textUnmarshalerType):
+		ptr := reflect.New(targetType.Elem())
+
+		return ptr, true, ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)) //nolint:forcetypeassert
+	case reflect.PtrTo(targetType).Implements(textUnmarshalerType):
+		ptr := reflect.New(targetType)
+		err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)) //nolint:forcetypeassert
+
+		return ptr.Elem(), true, err
+	default:
+		return reflect.Value{}, false, nil
+	}
+}
+
+func convertDuration(value reflect.Value) (reflect.Value, error) {
+	if value.Kind() == reflect.String {
+		d, err := time.ParseDuration(value.String())
+
+		return reflect.ValueOf(d), err
+	}
+
+	// numbers are interpreted as nanoseconds like time.Duration itself
+	n, err := convertNumber(value, reflect.TypeOf(int64(0)))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf(time.Duration(n.Int())), nil
+}
+
+func convertBool(value reflect.Value, targetType reflect.Type) (reflect.Value, error) {
+	if value.Kind() != reflect.String {
+		return reflect.Value{}, z.Newf("unsupported conversion from %s", value.Type())
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(value.String()))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf(b).Convert(targetType), nil
+}
+
+func convertNumber(value reflect.Value, targetType reflect.Type) (reflect.Value, error) {
+	if value.Kind() == reflect.String {
+		parsed, err := parseNumber(strings.TrimSpace(value.String()), targetType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		value = parsed
+	}
+
+	result := reflect.New(targetType).Elem()
+
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt(result, value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUint(result, value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return setFloat(result, value.Float())
+	}
+
+	return reflect.Value{}, z.Newf("unsupported conversion from %s", value.Type())
+}
+
+// parseNumber parses a string as the kind of number that is expected by the target type.
+func parseNumber(s string, targetType reflect.Type) (reflect.Value, error) {
+	switch targetType.Kind() { //nolint:exhaustive
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, 64)
+
+		return reflect.ValueOf(n), err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+
+		return reflect.ValueOf(f), err
+	default:
+		n, err := strconv.ParseInt(s, 0, 64)
+
+		return reflect.ValueOf(n), err
+	}
+}
+
+func setInt(result reflect.Value, n int64) (reflect.Value, error) {
+	switch result.Kind() { //nolint:exhaustive
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 {
+			return reflect.Value{}, z.Newf("%d is negative", n)
+		}
+
+		return setUint(result, uint64(n))
+	case reflect.Float32, reflect.Float64:
+		return setFloat(result, float64(n))
+	}
+
+	if result.OverflowInt(n) {
+		return reflect.Value{}, z.Newf("%d overflows %s", n, result.Type())
+	}
+
+	result.SetInt(n)
+
+	return result, nil
+}
+
+func setUint(result reflect.Value, n uint64) (reflect.Value, error) {
+	switch result.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n > math.MaxInt64 {
+			return reflect.Value{}, z.Newf("%d overflows %s", n, result.Type())
+		}
+
+		return setInt(result, int64(n))
+	case reflect.Float32, reflect.Float64:
+		return setFloat(result, float64(n))
+	}
+
+	if result.OverflowUint(n) {
+		return reflect.Value{}, z.Newf("%d overflows %s", n, result.Type())
+	}
+
+	result.SetUint(n)
+
+	return result, nil
+}
+
+func setFloat(result reflect.Value, f float64) (reflect.Value, error) {
+	switch result.Kind() { //nolint:exhaustive
+	case reflect.Float32, reflect.Float64:
+		if result.OverflowFloat(f) {
+			return reflect.Value{}, z.Newf("%v overflows %s", f, result.Type())
+		}
+
+		result.SetFloat(f)
+
+		return result, nil
+	}
+
+	if f != math.Trunc(f) || f < math.MinInt64 || f > math.MaxUint64 {
+		return reflect.Value{}, z.Newf("%v is not an integer", f)
+	}
+
+	if f < 0 {
+		return setInt(result, int64(f))
+	}
+
+	return setUint(result, uint64(f))
+}
+
+func convertSlice(value reflect.Value, targetType reflect.Type) (reflect.Value, error) {
+	if value.Kind() == reflect.String {
+		var parts []string
+		if s := strings.TrimSpace(value.String()); s != "" {
+			parts = strings.Split(s, ",")
+		}
+
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		value = reflect.ValueOf(parts)
+	}
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return reflect.Value{}, z.Newf("unsupported conversion from %s", value.Type())
+	}
+
+	result := reflect.MakeSlice(targetType, value.Len(), value.Len())
+
+	for i := 0; i < value.Len(); i++ {
+		elem, err := convertValue(value.Index(i), targetType.Elem())
+		if err != nil {
+			return reflect.Value{}, z.Wrapf(err, "invalid element %d", i)
+		}
+
+		result.Index(i).Set(elem)
+	}
+
+	return result, nil
+}
+
+func convertMap(value reflect.Value, targetType reflect.Type) (reflect.Value, error) {
+	if value.Kind() == reflect.String {
+		pairs := map[string]string{}
+
+		for _, pair := range strings.Split(value.String(), ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+
+			key, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return reflect.Value{}, z.Newf("invalid key value pair %q", pair)
+			}
+
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+
+		value = reflect.ValueOf(pairs)
+	}
+
+	if value.Kind() != reflect.Map {
+		return reflect.Value{}, z.Newf("unsupported conversion from %s", value.Type())
+	}
+
+	result := reflect.MakeMapWithSize(targetType, value.Len())
+	iter := value.MapRange()
+
+	for iter.Next() {
+		key, err := convertValue(iter.Key(), targetType.Key())
+		if err != nil {
+			return reflect.Value{}, z.Wrapf(err, "invalid key %v", iter.Key())
+		}
+
+		elem, err := convertValue(iter.Value(), targetType.Elem())
+		if err != nil {
+			return reflect.Value{}, z.Wrapf(err, "invalid value of key %v", iter.Key())
+		}
+
+		result.SetMapIndex(key, elem)
+	}
+
+	return result, nil
+}
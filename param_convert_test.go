@@ -0,0 +1,184 @@
+package di_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newParamContainer(t *testing.T, params map[string]interface{}, opts ...di.Option) *di.Container {
+	t.Helper()
+
+	pp := di.NewEnvParameterProvider(di.WithEnvPrefix("DI_TEST_UNSET"))
+	for key, value := range params {
+		assert.NoError(t, pp.Set(key, value))
+	}
+
+	return di.NewServiceContainer(append([]di.Option{di.WithParameterProvider(pp)}, opts...)...)
+}
+
+// getParam builds a service from a provider with a single parameter argument and returns the value it received.
+func getParam(t *testing.T, c *di.Container, provider interface{}, arg di.ServiceDefArg) (interface{}, error) {
+	t.Helper()
+
+	ref := di.StringRef(t.Name())
+	c.Register(di.NewServiceDef(ref).Provider(provider).Args(arg))
+
+	return c.Get(ref)
+}
+
+func TestParamArg_Conversion(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"string":   "10",
+		"int":      10,
+		"float":    2.5,
+		"bool":     "true",
+		"duration": "1m30s",
+		"list":     "a, b,c",
+		"ints":     []interface{}{"1", 2, 3.0},
+		"map":      "a=1,b=2",
+		"yaml":     map[string]interface{}{"a": "1", "b": 2},
+		"ip":       "127.0.0.1",
+	})
+
+	tests := []struct {
+		provider interface{}
+		param    string
+		expected interface{}
+	}{
+		{func(v int) int { return v }, "string", 10},
+		{func(v uint8) uint8 { return v }, "string", uint8(10)},
+		{func(v float64) float64 { return v }, "string", 10.0},
+		{func(v string) string { return v }, "int", "10"},
+		{func(v int64) int64 { return v }, "int", int64(10)},
+		{func(v float32) float32 { return v }, "float", float32(2.5)},
+		{func(v bool) bool { return v }, "bool", true},
+		{func(v time.Duration) time.Duration { return v }, "duration", 90 * time.Second},
+		{func(v time.Duration) time.Duration { return v }, "int", time.Duration(10)},
+		{func(v []string) []string { return v }, "list", []string{"a", "b", "c"}},
+		{func(v []int) []int { return v }, "ints", []int{1, 2, 3}},
+		{func(v map[string]int) map[string]int { return v }, "map", map[string]int{"a": 1, "b": 2}},
+		{func(v map[string]int) map[string]int { return v }, "yaml", map[string]int{"a": 1, "b": 2}},
+		{func(v net.IP) net.IP { return v }, "ip", net.ParseIP("127.0.0.1")},
+		{func(v *net.IP) string { return v.String() }, "ip", "127.0.0.1"},
+	}
+
+	for _, test := range tests {
+		name := reflect.TypeOf(test.provider).In(0).String() + " from " + test.param
+
+		t.Run(name, func(t *testing.T) {
+			v, err := getParam(t, c, test.provider, di.ParamArg(test.param))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, v)
+		})
+	}
+}
+
+func TestParamArg_Conversion_Error(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"string":   "foo",
+		"negative": -1,
+		"large":    300,
+		"float":    2.5,
+	})
+
+	tests := []struct {
+		provider interface{}
+		param    string
+	}{
+		{func(v int) int { return v }, "string"},
+		{func(v bool) bool { return v }, "string"},
+		{func(v time.Duration) time.Duration { return v }, "string"},
+		{func(v uint) uint { return v }, "negative"},
+		{func(v int8) int8 { return v }, "large"},
+		{func(v int) int { return v }, "float"},
+		{func(v net.IP) net.IP { return v }, "string"},
+		{func(v struct{}) struct{} { return v }, "string"},
+	}
+
+	for _, test := range tests {
+		name := reflect.TypeOf(test.provider).In(0).String() + " from " + test.param
+
+		t.Run(name, func(t *testing.T) {
+			_, err := getParam(t, c, test.provider, di.ParamArg(test.param))
+			assertErrorType(t, err, di.ParamConversionError)
+			assert.Contains(t, err.Error(), "invalid parameter "+test.param)
+		})
+	}
+}
+
+func TestParamArgAs(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{
+		"timeout": "5s",
+		"port":    "8080",
+	})
+
+	v, err := getParam(t, c, func(v interface{}) interface{} { return v }, di.ParamArgAs[time.Duration]("timeout"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, v)
+
+	c.Register(
+		di.NewServiceDef(di.StringRef("server")).
+			Provider(func(port string) string { return port }).
+			Args(di.ParamArgAs[int]("port")),
+		di.NewServiceDef(di.StringRef("timeout")).
+			Provider(func(timeout time.Duration) time.Duration { return timeout }).
+			Args(di.ParamArgAs[time.Duration]("port")),
+	)
+
+	err = c.Validate()
+	assertErrorType(t, err, di.ContainerValidationError)
+	assert.Contains(t, err.Error(), "invalid service server")
+	assert.Contains(t, err.Error(), "expected string got int")
+	assert.Contains(t, err.Error(), "parameter port cannot be resolved")
+}
+
+func TestParamArgAs_TypeMismatch(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{"port": "10"})
+
+	// the declared type is enforced on Get as it is on Validate
+	_, err := getParam(t, c, func(port string) string { return port }, di.ParamArgAs[int]("port"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "invalid parameter port")
+	assert.Contains(t, err.Error(), "expected string got int")
+}
+
+func TestParamArgWithDefault(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{"db.port": "5433", "db.host": "localhost"},
+		di.WithParamInterpolation(),
+	)
+
+	v, err := getParam(t, c, func(v int) int { return v }, di.ParamArgWithDefault("db.port", 5432))
+	assert.NoError(t, err)
+	assert.Equal(t, 5433, v)
+
+	v, err = getParam(t, c, func(v int) int { return v }, di.ParamArgWithDefault("db.pool.size", "10"))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, v)
+
+	v, err = getParam(t, c, func(v string) string { return v }, di.ParamArgWithDefault("db.dsn", "%db.host%:5432"))
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:5432", v)
+}
+
+func TestOptionalParamArg(t *testing.T) {
+	c := di.NewServiceContainer()
+
+	v, err := getParam(t, c, func(v int) int { return v }, di.OptionalParamArg("db.port"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v)
+
+	v, err = getParam(t, c, func(v *net.IP) *net.IP { return v }, di.OptionalParamArg("db.ip"))
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	c.Register(
+		di.NewServiceDef(di.StringRef("optional")).
+			Provider(func(v []string) []string { return v }).
+			Args(di.OptionalParamArg("hosts")),
+	)
+	assert.NoError(t, c.Validate())
+}
@@ -0,0 +1,302 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di/internal/pkg/utils"
+	z "github.com/dtomasi/zerrors"
+	"github.com/hashicorp/go-multierror"
+	"strings"
+	"time"
+)
+
+// ParamChange describes a parameter whose value has changed.
+type ParamChange struct {
+	Key      string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// WatchableParameterProvider is implemented by ParameterProviders that can report changes of their parameters.
+// Watch sends the changes until ctx is done and closes the channel afterwards.
+type WatchableParameterProvider interface {
+	ParameterProvider
+	Watch(ctx context.Context) <-chan ParamChange
+}
+
+// ParamChangeListener is implemented by services that apply parameter changes themselves.
+// Instead of rebuilding the service, the container calls OnParamChange with the changes of the parameters the
+// service depends on.
+type ParamChangeListener interface {
+	OnParamChange(ctx context.Context, changes []ParamChange) error
+}
+
+// WatchOption defines an option function for WatchParameters.
+type WatchOption func(wo *watchOptions)
+
+// watchOptions holds the options of WatchParameters.
+type watchOptions struct {
+	disposeDelay time.Duration
+}
+
+// defaultDisposeDelay is how long replaced instances stay usable by default.
+const defaultDisposeDelay = 10 * time.Second
+
+func newWatchOptions() *watchOptions {
+	return &watchOptions{
+		disposeDelay: defaultDisposeDelay,
+	}
+}
+
+// WithDisposeDelay defines how long a replaced instance stays usable for requests that still hold it before it is
+// disposed. A delay of zero disposes replaced instances right away. Defaults to 10 seconds.
+func WithDisposeDelay(delay time.Duration) WatchOption {
+	return func(wo *watchOptions) {
+		wo.disposeDelay = delay
+	}
+}
+
+// paramDependent is implemented by arguments that are resolved using parameters.
+type paramDependent interface {
+	paramKeys(c *Container) []string
+}
+
+// WatchParameters watches the ParameterProvider for changes until ctx or the container context is done.
+// When parameters change, all built services that depend on them are updated in dependency order: services
+// implementing ParamChangeListener are notified, all others are rebuilt and replaced, as well as the services
+// that depend on a replaced service. Started instances are stopped and their replacements are started. As replaced
+// instances may still be in use, they are disposed after a delay, see WithDisposeDelay. Errors of disposing are
+// logged. If an update fails, the service keeps its current instance.
+// After each update EventTopicParamsChanged is published.
+func (c *Container) WatchParameters(ctx context.Context, opts ...WatchOption) error {
+	options := newWatchOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	watchable, ok := c.paramProvider.(WatchableParameterProvider)
+	if !ok {
+		return z.NewWithOpts(
+			fmt.Sprintf("parameter provider %T does not support watching", c.paramProvider),
+			z.WithType(ParamProviderNotWatchableError),
+		)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	changes := watchable.Watch(ctx)
+
+	go func() {
+		defer cancel()
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case change, ok := <-changes:
+				if !ok {
+					return
+				}
+
+				c.applyParamChanges(ctx, append([]ParamChange{change}, drainParamChanges(changes)...), options)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// drainParamChanges returns all changes that are already waiting, so they are applied at once.
+func drainParamChanges(changes <-chan ParamChange) (pending []ParamChange) {
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				return pending
+			}
+
+			pending = append(pending, change)
+		default:
+			return pending
+		}
+	}
+}
+
+// applyParamChanges updates all built services that depend on the changed parameters.
+func (c *Container) applyParamChanges(ctx context.Context, changes []ParamChange, options *watchOptions) {
+	event := ParamsChangedEvent{Changes: changes, Rebuilt: nil, Notified: nil, Err: nil}
+
+	var built []fmt.Stringer
+
+	_ = c.serviceDefs.Range(func(key fmt.Stringer, def *ServiceDef) error {
+		if def.provider != nil && !def.options.alwaysRebuild && def.getInstance() != nil {
+			built = append(built, key)
+		}
+
+		return nil
+	})
+
+	replaced := map[fmt.Stringer]bool{}
+
+	for _, ref := range c.sortByDependencies(built) {
+		def, ok := c.serviceDefs.Load(ref)
+		if !ok {
+			continue
+		}
+
+		direct := c.affectingChanges(def, changes)
+
+		if listener, ok := def.getInstance().(ParamChangeListener); ok && len(direct) > 0 &&
+			!dependsOnAny(c.dependenciesOf(def), replaced) {
+			if err := listener.OnParamChange(ctx, direct); err != nil {
+				event.Err = multierror.Append(event.Err, z.Wrapf(err, "could not notify service %s", ref))
+
+				continue
+			}
+
+			event.Notified = append(event.Notified, ref)
+
+			continue
+		}
+
+		if len(direct) == 0 && !dependsOnAny(c.dependenciesOf(def), replaced) {
+			continue
+		}
+
+		if err := c.replaceInstance(def, options.disposeDelay); err != nil {
+			event.Err = multierror.Append(event.Err, err)
+
+			continue
+		}
+
+		replaced[ref] = true
+		event.Rebuilt = append(event.Rebuilt, ref)
+	}
+
+	if event.Err != nil {
+		c.logger.Error(event.Err, "could not apply parameter changes")
+	}
+
+	c.logger.V(utils.LogLevelDebug).Info("applied parameter changes",
+		"rebuilt", refNames(event.Rebuilt), "notified", refNames(event.Notified))
+	c.events.publish(EventTopicParamsChanged, event)
+}
+
+// replaceInstance builds a new instance of given definition and replaces the current one.
+// If the current instance was started, it is stopped before and the new instance is started after the swap. The
+// current instance is disposed after given delay.
+func (c *Container) replaceInstance(def *ServiceDef, disposeDelay time.Duration) error {
+	instance, err := c.buildService(def, newPendingBuild(def), nil)
+	if err != nil {
+		return err
+	}
+
+	started := def.getState() == serviceStarted
+
+	if err = c.stopService(c.ctx, def); err != nil {
+		return err
+	}
+
+	def.mu.Lock()
+	replaced := def.instance
+	def.instance = instance
+	def.state = serviceBuilt
+	def.mu.Unlock()
+
+	c.disposeReplaced(def, replaced, disposeDelay)
+
+	if started {
+		return c.startService(def, instance)
+	}
+
+	return nil
+}
+
+// disposeReplaced disposes a replaced instance of the service after given delay.
+func (c *Container) disposeReplaced(def *ServiceDef, instance interface{}, delay time.Duration) {
+	dispose := func() {
+		c.logger.V(utils.LogLevelDebug).Info("disposing replaced service", "name", def.ref.String())
+
+		if err := disposeInstance(context.Background(), def, instance); err != nil {
+			c.logger.Error(err, "could not dispose replaced service", "name", def.ref.String())
+		}
+	}
+
+	if delay <= 0 {
+		dispose()
+
+		return
+	}
+
+	time.AfterFunc(delay, dispose)
+}
+
+// affectingChanges returns the changes of all parameters the definition depends on.
+func (c *Container) affectingChanges(def *ServiceDef, changes []ParamChange) (affecting []ParamChange) {
+	args, _ := c.resolveArgs(def)
+	keys := argParamKeys(c, joinArgs(args, callArgs(def), c.decoratorArgs(def)))
+
+	for _, change := range changes {
+		for _, key := range keys {
+			if paramKeysOverlap(key, change.Key) {
+				affecting = append(affecting, change)
+
+				break
+			}
+		}
+	}
+
+	return affecting
+}
+
+func dependsOnAny(deps []fmt.Stringer, refs map[fmt.Stringer]bool) bool {
+	for _, dep := range deps {
+		if refs[dep] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// argParamKeys returns the keys of all parameters that are used by given args.
+func argParamKeys(c *Container, args []ServiceDefArg) []string {
+	var keys []string
+
+	for _, arg := range args {
+		if p, ok := arg.(paramDependent); ok {
+			keys = append(keys, p.paramKeys(c)...)
+		}
+	}
+
+	return keys
+}
+
+// paramKeysOf returns the key and the keys of all parameters that are referenced by placeholders in its value if
+// parameter values are interpolated.
+func (c *Container) paramKeysOf(key string, visited map[string]bool) []string {
+	if visited[key] {
+		return nil
+	}
+
+	visited[key] = true
+	keys := []string{key}
+
+	if !c.interpolateParams {
+		return keys
+	}
+
+	if value, err := c.paramProvider.Get(key); err == nil {
+		if s, ok := value.(string); ok {
+			for _, ref := range placeholderKeys(s) {
+				keys = append(keys, c.paramKeysOf(ref, visited)...)
+			}
+		}
+	}
+
+	return keys
+}
+
+// paramKeysOverlap returns true if a change of one key affects the other, e.g. db and db.host.
+func paramKeysOverlap(a string, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
@@ -0,0 +1,245 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/dtomasi/go-event-bus/v3"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchableParameterProvider is a ParameterProvider that sends changes made by Change to its watcher.
+type watchableParameterProvider struct {
+	*di.EnvParameterProvider
+	changes chan di.ParamChange
+}
+
+func newWatchableParameterProvider(params map[string]interface{}) *watchableParameterProvider {
+	pp := &watchableParameterProvider{
+		EnvParameterProvider: di.NewEnvParameterProvider(di.WithEnvPrefix("DI_TEST_UNSET")),
+		changes:              make(chan di.ParamChange, 10),
+	}
+
+	for key, value := range params {
+		_ = pp.Set(key, value)
+	}
+
+	return pp
+}
+
+func (p *watchableParameterProvider) Watch(_ context.Context) <-chan di.ParamChange {
+	return p.changes
+}
+
+func (p *watchableParameterProvider) Change(key string, value interface{}) {
+	old, _ := p.Get(key)
+	_ = p.Set(key, value)
+	p.changes <- di.ParamChange{Key: key, OldValue: old, NewValue: value}
+}
+
+// levelLogger applies log level changes itself.
+type levelLogger struct {
+	mu    sync.Mutex
+	level string
+}
+
+func (l *levelLogger) Level() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.level
+}
+
+func (l *levelLogger) OnParamChange(_ context.Context, changes []di.ParamChange) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.level = changes[len(changes)-1].NewValue.(string) //nolint:forcetypeassert
+
+	return nil
+}
+
+type rateLimiter struct {
+	limit  int
+	logger *levelLogger
+}
+
+type handler struct {
+	limiter *rateLimiter
+}
+
+func subscribeParamsChanged(eb *eventbus.EventBus) <-chan di.ParamsChangedEvent {
+	events := make(chan di.ParamsChangedEvent, 10)
+	ch := eb.Subscribe(di.EventTopicParamsChanged.String())
+
+	go func() {
+		for evt := range ch {
+			events <- evt.Data.(di.ParamsChangedEvent) //nolint:forcetypeassert
+			evt.Done()
+		}
+	}()
+
+	return events
+}
+
+func waitParamsChanged(t *testing.T, events <-chan di.ParamsChangedEvent) di.ParamsChangedEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for parameter changes")
+	}
+
+	return di.ParamsChangedEvent{} //nolint:exhaustivestruct
+}
+
+func TestContainer_WatchParameters(t *testing.T) {
+	pp := newWatchableParameterProvider(map[string]interface{}{
+		"log.level":  "info",
+		"rate.limit": "10",
+		"unused":     "foo",
+	})
+	eb := eventbus.NewEventBus()
+	events := subscribeParamsChanged(eb)
+
+	container := di.NewServiceContainer(di.WithParameterProvider(pp), di.WithEventBus(eb))
+	container.Register(
+		di.NewServiceDef(di.StringRef("logger")).
+			Provider(func(level string) *levelLogger { return &levelLogger{level: level} }). //nolint:exhaustivestruct
+			Args(di.ParamArg("log.level")),
+		di.NewServiceDef(di.StringRef("limiter")).
+			Provider(func(limit int, logger *levelLogger) *rateLimiter {
+				return &rateLimiter{limit: limit, logger: logger}
+			}).
+			Args(di.ParamArg("rate.limit"), di.ServiceArg(di.StringRef("logger"))),
+		di.NewServiceDef(di.StringRef("handler")).
+			Provider(func(limiter *rateLimiter) *handler { return &handler{limiter: limiter} }).
+			Args(di.ServiceArg(di.StringRef("limiter"))),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.NoError(t, container.WatchParameters(context.Background()))
+
+	logger := di.MustGet[*levelLogger](container, di.StringRef("logger"))
+	limiter := di.MustGet[*rateLimiter](container, di.StringRef("limiter"))
+	h := di.MustGet[*handler](container, di.StringRef("handler"))
+
+	pp.Change("log.level", "debug")
+
+	event := waitParamsChanged(t, events)
+	assert.NoError(t, event.Err)
+	assert.Equal(t, []string{"logger"}, refNamesOf(event.Notified))
+	assert.Empty(t, event.Rebuilt)
+	assert.Equal(t, "debug", logger.Level())
+	assert.Same(t, limiter, di.MustGet[*rateLimiter](container, di.StringRef("limiter")))
+
+	pp.Change("rate.limit", "20")
+
+	event = waitParamsChanged(t, events)
+	assert.NoError(t, event.Err)
+	assert.Equal(t, []string{"limiter", "handler"}, refNamesOf(event.Rebuilt))
+
+	newLimiter := di.MustGet[*rateLimiter](container, di.StringRef("limiter"))
+	assert.NotSame(t, limiter, newLimiter)
+	assert.Equal(t, 20, newLimiter.limit)
+	assert.Same(t, logger, newLimiter.logger)
+	assert.Same(t, newLimiter, di.MustGet[*handler](container, di.StringRef("handler")).limiter)
+	assert.NotSame(t, h, di.MustGet[*handler](container, di.StringRef("handler")))
+
+	pp.Change("rate.limit", "invalid")
+
+	event = waitParamsChanged(t, events)
+	assert.Error(t, event.Err)
+	assert.Contains(t, event.Err.Error(), "error while building service limiter")
+	assert.Empty(t, event.Rebuilt)
+	assert.Same(t, newLimiter, di.MustGet[*rateLimiter](container, di.StringRef("limiter")))
+
+	assert.NoError(t, container.Close(context.Background()))
+}
+
+func TestContainer_WatchParameters_NotWatchable(t *testing.T) {
+	container := di.NewServiceContainer()
+
+	err := container.WatchParameters(context.Background())
+	assertErrorType(t, err, di.ParamProviderNotWatchableError)
+}
+
+func refNamesOf(refs []fmt.Stringer) []string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.String())
+	}
+
+	return names
+}
+
+func TestContainer_WatchParameters_RestartsStartedServices(t *testing.T) {
+	var lifecycle []string
+
+	pp := newWatchableParameterProvider(map[string]interface{}{"server.name": "old"})
+	eb := eventbus.NewEventBus()
+	events := subscribeParamsChanged(eb)
+
+	container := di.NewServiceContainer(di.WithParameterProvider(pp), di.WithEventBus(eb))
+	container.Register(
+		di.NewServiceDef(di.StringRef("server")).
+			Provider(func(name string) *lifecycleService {
+				return &lifecycleService{name: name, events: &lifecycle, startErr: nil}
+			}).
+			Args(di.ParamArg("server.name")),
+	)
+
+	assert.NoError(t, container.Build())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, container.WatchParameters(ctx))
+
+	pp.Change("server.name", "new")
+
+	event := waitParamsChanged(t, events)
+	assert.NoError(t, event.Err)
+	assert.Equal(t, []string{"init:old", "start:old", "init:new", "stop:old", "start:new"}, lifecycle)
+
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"stop:new"}, lifecycle[5:])
+}
+
+func TestContainer_WatchParameters_DisposesReplacedInstances(t *testing.T) {
+	var closed []string
+
+	pp := newWatchableParameterProvider(map[string]interface{}{"db.name": "old"})
+	eb := eventbus.NewEventBus()
+	events := subscribeParamsChanged(eb)
+
+	container := di.NewServiceContainer(di.WithParameterProvider(pp), di.WithEventBus(eb))
+	container.Register(
+		di.NewServiceDef(di.StringRef("db")).
+			Provider(func(name string) *closeRecorder {
+				return &closeRecorder{name: name, closed: &closed} //nolint:exhaustivestruct
+			}).
+			Args(di.ParamArg("db.name")),
+	)
+
+	assert.NoError(t, container.Build())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, container.WatchParameters(ctx, di.WithDisposeDelay(0)))
+
+	pp.Change("db.name", "new")
+
+	event := waitParamsChanged(t, events)
+	assert.NoError(t, event.Err)
+	assert.Equal(t, []string{"old"}, closed)
+
+	assert.NoError(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"old", "new"}, closed)
+}
@@ -0,0 +1,37 @@
+package di
+
+import (
+	"sync"
+)
+
+// ProviderRegistry maps names to provider functions.
+// It is used to resolve providers of service definitions that are loaded from a file via LoadDefinitions.
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]interface{}
+}
+
+// NewProviderRegistry returns a new ProviderRegistry instance.
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{ //nolint:exhaustivestruct
+		providers: map[string]interface{}{},
+	}
+}
+
+// Register adds a provider function with the name it can be referenced by.
+func (r *ProviderRegistry) Register(name string, provider interface{}) *ProviderRegistry {
+	r.mu.Lock()
+	r.providers[name] = provider
+	r.mu.Unlock()
+
+	return r
+}
+
+// Lookup returns the provider function registered with given name.
+func (r *ProviderRegistry) Lookup(name string) (provider interface{}, ok bool) {
+	r.mu.RLock()
+	provider, ok = r.providers[name]
+	r.mu.RUnlock()
+
+	return provider, ok
+}
@@ -0,0 +1,18 @@
+package di_test
+
+import (
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	r := di.NewProviderRegistry().Register("NewTestService1", NewTestService1)
+
+	provider, ok := r.Lookup("NewTestService1")
+	assert.True(t, ok)
+	assert.NotNil(t, provider)
+
+	_, ok = r.Lookup("NewTestService2")
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di/internal/pkg/utils"
+	z "github.com/dtomasi/zerrors"
+)
+
+// NewScope returns a child container that inherits all service definitions of the container.
+// Services defined with the Scoped option are built once per scope, all other services are shared with the
+// parent container. The scope uses given context, so ContextArg yields the scope context for scoped services.
+// Close the scope to dispose its scoped services and to cancel the scope context.
+func (c *Container) NewScope(ctx context.Context) *Container {
+	scope := &Container{
+		ctx:               ctx,
+		ctxCancelFun:      nil,
+		logger:            c.logger,
+		eventBus:          c.eventBus,
+		events:            c.events,
+		builds:            c.builds,
+		lifecycle:         newContainerLifecycle(),
+		chain:             nil,
+		origin:            nil,
+		paramProvider:     c.paramProvider,
+		serviceDefs:       NewServiceDefMap(),
+		aliases:           newAliasMap(),
+		decorators:        newDecoratorMap(),
+		autowire:          c.autowire,
+		interpolateParams: c.interpolateParams,
+		parent:            c,
+	}
+
+	scope.ctx, scope.ctxCancelFun = context.WithCancel(scope.ctx)
+	scope.ctx = context.WithValue(scope.ctx, ContextKeyContainer, scope)
+
+	c.logger.V(utils.LogLevelDebug).Info("created a new scope")
+
+	return scope
+}
+
+// loadServiceDef returns the definition for ref from the container or one of its parents.
+// Aliases are resolved to the definition they point to.
+func (c *Container) loadServiceDef(ref fmt.Stringer) (*ServiceDef, bool) {
+	ref = c.canonicalRef(ref)
+
+	for container := c; container != nil; container = container.parent {
+		if def, ok := container.serviceDefs.Load(ref); ok {
+			return def, true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveServiceDef returns the definition for ref and the container that owns its instance.
+// Scoped definitions of a parent are copied into the scope on first request to hold the scope instance.
+func (c *Container) resolveServiceDef(ref fmt.Stringer) (*ServiceDef, *Container, error) {
+	if def, ok := c.serviceDefs.Load(ref); ok {
+		if def.options.scoped && c.parent == nil {
+			return nil, nil, z.NewWithOpts(
+				fmt.Sprintf("service %s is scoped and can only be requested within a scope", ref),
+				z.WithType(ServiceScopeError),
+			)
+		}
+
+		return def, c, nil
+	}
+
+	if c.parent == nil {
+		return nil, nil, z.NewWithOpts(
+			fmt.Sprintf("services %s not found", ref),
+			z.WithType(ServiceNotFoundError),
+		)
+	}
+
+	def, ok := c.parent.loadServiceDef(ref)
+	if ok && def.options.scoped {
+		def, _ = c.serviceDefs.LoadOrStore(ref, def.Clone())
+
+		return def, c, nil
+	}
+
+	return c.parent.resolveServiceDef(ref)
+}
+
+// allServiceDefs returns all definitions that are visible to the container, including the inherited ones.
+func (c *Container) allServiceDefs() map[fmt.Stringer]*ServiceDef {
+	defs := map[fmt.Stringer]*ServiceDef{}
+
+	if c.parent != nil {
+		defs = c.parent.allServiceDefs()
+	}
+
+	_ = c.serviceDefs.Range(func(key fmt.Stringer, def *ServiceDef) error {
+		defs[key] = def
+
+		return nil
+	})
+
+	return defs
+}
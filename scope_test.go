@@ -0,0 +1,73 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type scopeCtxKey struct{}
+
+func newScopeTestContainer(closed *[]string) *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("singleton")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "singleton", closed: closed} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("request")).
+			Opts(di.Scoped()).
+			Provider(func(ctx context.Context, s *closeRecorder) *closeRecorder {
+				name := fmt.Sprintf("request-%v", ctx.Value(scopeCtxKey{}))
+
+				return &closeRecorder{name: name, closed: closed} //nolint:exhaustivestruct
+			}).
+			Args(di.ContextArg(), di.ServiceArg(di.StringRef("singleton"))).
+			Tags(di.StringRef("scoped")),
+	)
+
+	return container
+}
+
+func TestContainer_NewScope(t *testing.T) {
+	var closed []string
+
+	container := newScopeTestContainer(&closed)
+	assert.NoError(t, container.Build())
+
+	scope1 := container.NewScope(context.WithValue(context.Background(), scopeCtxKey{}, 1))
+	scope2 := container.NewScope(context.WithValue(context.Background(), scopeCtxKey{}, 2))
+
+	r1 := di.MustGet[*closeRecorder](scope1, di.StringRef("request"))
+	r2 := di.MustGet[*closeRecorder](scope2, di.StringRef("request"))
+
+	assert.Equal(t, "request-1", r1.Name())
+	assert.Equal(t, "request-2", r2.Name())
+	assert.Same(t, r1, di.MustGet[*closeRecorder](scope1, di.StringRef("request")))
+	assert.Same(t,
+		di.MustGet[*closeRecorder](container, di.StringRef("singleton")),
+		di.MustGet[*closeRecorder](scope1, di.StringRef("singleton")),
+	)
+
+	tagged, err := di.FindByTags[*closeRecorder](scope2, []fmt.Stringer{di.StringRef("scoped")})
+	assert.NoError(t, err)
+	assert.Equal(t, []*closeRecorder{r2}, tagged)
+
+	scopeContainer, err := di.GetContainerFromContext(scope1.GetContext())
+	assert.NoError(t, err)
+	assert.Equal(t, scope1, scopeContainer)
+
+	assert.NoError(t, scope1.Close(context.Background()))
+	assert.Equal(t, []string{"request-1"}, closed)
+	assert.Error(t, scope1.GetContext().Err())
+	assert.NoError(t, container.GetContext().Err())
+}
+
+func TestContainer_NewScope_ScopedFromRoot(t *testing.T) {
+	container := newScopeTestContainer(&[]string{})
+
+	_, err := container.Get(di.StringRef("request"))
+	assertErrorType(t, err, di.ServiceScopeError)
+}
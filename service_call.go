@@ -0,0 +1,196 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"reflect"
+)
+
+// methodCall is a method of the service instance that is called after the provider returned.
+type methodCall struct {
+	method string
+	args   []ServiceDefArg
+}
+
+// Call adds a method that is called on the service instance after the provider returned.
+// Calls are executed in the order they are defined and before the instance is returned to anyone else.
+// The method may return nothing, an error or a value and an error. Returned values are ignored.
+//
+// Calls are the way to break circular constructor dependencies: a service that is referenced by a call arg is
+// not a dependency of the provider, so both services can be built. If the referenced service requested the
+// service while it is being built itself, the call is executed once its provider returned and before it is
+// returned to anyone else. If such a call fails or the referenced service fails to build, both services are built
+// again on next request. Services that are built by other requests are waited for.
+func (sd *ServiceDef) Call(method string, args ...ServiceDefArg) *ServiceDef {
+	sd.calls = append(sd.calls, methodCall{method: method, args: args})
+
+	return sd
+}
+
+// callArgs returns the args of all calls of the definition.
+func callArgs(def *ServiceDef) []ServiceDefArg {
+	var args []ServiceDefArg
+
+	for _, call := range def.calls {
+		args = append(args, call.args...)
+	}
+
+	return args
+}
+
+// applyCalls executes given calls on the instance in order.
+// If a call references a service that is being built by the chain of this build, the call and all following ones
+// are deferred until that build has constructed the instance.
+func (c *Container) applyCalls(def *ServiceDef, instance interface{}, calls []methodCall) error {
+	for i, call := range calls {
+		if pending := c.pendingDependency(call); pending != nil {
+			remaining := calls[i:]
+
+			deferred := pending.deferUntilBuilt(def, func() (err error) {
+				defer z.WrapPtrWithOpts(&err,
+					fmt.Sprintf("error while building service %s", def.ref),
+					z.WithType(ServiceBuildError),
+				)
+
+				return c.applyCalls(def, instance, remaining)
+			})
+			if deferred {
+				return nil
+			}
+		}
+
+		if err := c.callMethod(instance, call); err != nil {
+			return z.Wrapf(err, "could not call %s", call.method)
+		}
+	}
+
+	return nil
+}
+
+// callMethod calls the method on given instance.
+func (c *Container) callMethod(instance interface{}, call methodCall) error {
+	method := reflect.ValueOf(instance).MethodByName(call.method)
+	if !method.IsValid() {
+		return z.NewWithOpts(
+			fmt.Sprintf("method %s not found on %T", call.method, instance),
+			z.WithType(CallableNotAFuncError),
+		)
+	}
+
+	// unlike providers, methods do not need to return a value.
+	returnValues, err := c.callReflectValue(method, call.args)
+	if err != nil {
+		return err
+	}
+
+	// an error as last return value is the result of the call.
+	if len(returnValues) > 0 {
+		if callErr, ok := returnValues[len(returnValues)-1].Interface().(error); ok {
+			return callErr
+		}
+	}
+
+	return nil
+}
+
+// pendingDependency returns the definition of a service referenced by the call that is being built by the chain
+// of this build and not constructed yet. Waiting for it would never end, while builds of other requests are
+// waited for when the call args are evaluated.
+func (c *Container) pendingDependency(call methodCall) *ServiceDef {
+	for _, ref := range argDependencies(c, call.args) {
+		def, ok := c.loadServiceDef(ref)
+		if !ok {
+			continue
+		}
+
+		if build := def.constructingBuild(); build != nil && c.builds.inChain(c.chain, build) {
+			return def
+		}
+	}
+
+	return nil
+}
+
+// constructingBuild returns the build of the service if its provider did not return yet.
+func (sd *ServiceDef) constructingBuild() *pendingBuild {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if sd.instance != nil || sd.pending == nil || sd.pending.constructed != nil {
+		return nil
+	}
+
+	return sd.pending
+}
+
+// deferredCall is a call of a service that waits for the build of another service.
+type deferredCall struct {
+	// def is the definition of the service the call belongs to
+	def *ServiceDef
+	run func() error
+}
+
+// deferUntilBuilt registers fn of the service defined by owner to be called once the pending build constructed
+// its instance. It returns false if there is no build whose provider did not return yet.
+func (sd *ServiceDef) deferUntilBuilt(owner *ServiceDef, fn func() error) bool {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if sd.instance != nil || sd.pending == nil || sd.pending.constructed != nil {
+		return false
+	}
+
+	sd.pending.deferred = append(sd.pending.deferred, deferredCall{def: owner, run: fn})
+
+	return true
+}
+
+// runDeferredCalls executes the calls that wait for the build before the instance is published, so a failing
+// call fails the build as well. If the build failed with err, the calls are skipped. In both cases the services
+// the skipped or failed calls belong to are incomplete, so their instances are dropped to build them again on
+// next request. The error of the build is returned.
+func (c *Container) runDeferredCalls(sd *ServiceDef, build *pendingBuild, err error) error {
+	sd.mu.Lock()
+	deferred := build.deferred
+	build.deferred = nil
+	sd.mu.Unlock()
+
+	for _, call := range deferred {
+		if err == nil {
+			if err = call.run(); err == nil {
+				continue
+			}
+		}
+
+		call.def.takeInstance()
+	}
+
+	return err
+}
+
+// validateCalls checks that the called methods exist and match their args if the service type is known.
+func (c *Container) validateCalls(def *ServiceDef) (errs []error) {
+	for _, call := range def.calls {
+		methodType, err := methodType(def.producedType(), call.method)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if methodType != nil && methodType.NumIn() != len(call.args) {
+			errs = append(errs, z.NewWithOpts(
+				fmt.Sprintf("method %s expects %d args got %d", call.method, methodType.NumIn(), len(call.args)),
+				z.WithType(CallableArgCountMismatchError),
+			))
+
+			continue
+		}
+
+		for _, argErr := range c.validateArgs(call.args, methodType) {
+			errs = append(errs, z.Wrapf(argErr, "invalid call %s", call.method))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,253 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type callRecorder struct {
+	calls []string
+	peer  *callPeer
+}
+
+func (r *callRecorder) SetName(name string) {
+	r.calls = append(r.calls, "name:"+name)
+}
+
+func (r *callRecorder) SetLevel(level int) error {
+	if level < 0 {
+		return errors.New("negative level")
+	}
+
+	r.calls = append(r.calls, "level")
+
+	return nil
+}
+
+func (r *callRecorder) SetPeer(peer *callPeer) {
+	r.peer = peer
+}
+
+type callPeer struct {
+	recorder *callRecorder
+}
+
+func TestServiceDef_Call(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Set(di.StringRef("name"), "first")
+	container.Register(
+		di.NewServiceDef(di.StringRef("recorder")).
+			Provider(func() *callRecorder { return &callRecorder{} }).
+			Call("SetName", di.ServiceArg(di.StringRef("name"))).
+			Call("SetLevel", di.InterfaceArg(1)).
+			Call("SetName", di.InterfaceArg("second")),
+	)
+
+	assert.NoError(t, container.Validate())
+
+	recorder, err := di.Get[*callRecorder](container, di.StringRef("recorder"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name:first", "level", "name:second"}, recorder.calls)
+}
+
+func TestServiceDef_Call_Error(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("recorder")).
+			Provider(func() *callRecorder { return &callRecorder{} }).
+			Call("SetLevel", di.InterfaceArg(-1)),
+	)
+
+	_, err := container.Get(di.StringRef("recorder"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "negative level")
+}
+
+func TestServiceDef_Call_MethodNotFound(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("recorder")).
+			Provider(func() *callRecorder { return &callRecorder{} }).
+			Call("SetMissing"),
+	)
+
+	err := container.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "method SetMissing not found")
+
+	_, err = container.Get(di.StringRef("recorder"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "method SetMissing not found")
+}
+
+func newCallCycleContainer() *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("recorder")).
+			Provider(func() *callRecorder { return &callRecorder{} }).
+			Call("SetPeer", di.ServiceArg(di.StringRef("peer"))),
+		di.NewServiceDef(di.StringRef("peer")).
+			Provider(func(recorder *callRecorder) *callPeer {
+				return &callPeer{recorder: recorder}
+			}).
+			Args(di.ServiceArg(di.StringRef("recorder"))),
+	)
+
+	return container
+}
+
+func TestServiceDef_Call_BreaksCycle(t *testing.T) {
+	for _, ref := range []string{"recorder", "peer"} {
+		t.Run(ref, func(t *testing.T) {
+			container := newCallCycleContainer()
+			assert.NoError(t, container.Validate())
+
+			_, err := container.Get(di.StringRef(ref))
+			assert.NoError(t, err)
+
+			recorder := di.MustGet[*callRecorder](container, di.StringRef("recorder"))
+			peer := di.MustGet[*callPeer](container, di.StringRef("peer"))
+
+			assert.Same(t, peer, recorder.peer)
+			assert.Same(t, recorder, peer.recorder)
+		})
+	}
+}
+
+type cyclicA struct {
+	b *cyclicB
+}
+
+type cyclicB struct {
+	a    *cyclicA
+	fail bool
+}
+
+func (b *cyclicB) SetA(a *cyclicA) error {
+	if b.fail {
+		return errors.New("set a failed")
+	}
+
+	b.a = a
+
+	return nil
+}
+
+// newDeferredCallContainer returns a container where the call of b is deferred until a is constructed.
+func newDeferredCallContainer(provideA func(b *cyclicB) (*cyclicA, error), failCall bool) *di.Container {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("a")).
+			Provider(provideA).
+			Args(di.ServiceArg(di.StringRef("b"))),
+		di.NewServiceDef(di.StringRef("b")).
+			Provider(func() *cyclicB { return &cyclicB{fail: failCall} }).
+			Call("SetA", di.ServiceArg(di.StringRef("a"))),
+	)
+
+	return container
+}
+
+func provideCyclicA(b *cyclicB) (*cyclicA, error) {
+	return &cyclicA{b: b}, nil
+}
+
+func TestServiceDef_Call_Deferred(t *testing.T) {
+	container := newDeferredCallContainer(provideCyclicA, false)
+
+	a, err := di.Get[*cyclicA](container, di.StringRef("a"))
+	assert.NoError(t, err)
+	assert.Same(t, a, a.b.a)
+}
+
+func TestServiceDef_Call_DeferredCallError(t *testing.T) {
+	container := newDeferredCallContainer(provideCyclicA, true)
+
+	// both services fail on each request instead of returning half-wired instances
+	for i := 0; i < 2; i++ {
+		_, err := container.Get(di.StringRef("a"))
+		assertErrorType(t, err, di.ServiceBuildError)
+		assert.Contains(t, err.Error(), "could not call SetA: set a failed")
+
+		_, err = container.Get(di.StringRef("b"))
+		assertErrorType(t, err, di.ServiceBuildError)
+		assert.Contains(t, err.Error(), "set a failed")
+	}
+}
+
+func TestServiceDef_Call_DeferredBuildFailed(t *testing.T) {
+	container := newDeferredCallContainer(func(b *cyclicB) (*cyclicA, error) {
+		return nil, errors.New("a failed")
+	}, false)
+
+	_, err := container.Get(di.StringRef("a"))
+	assert.Error(t, err)
+
+	// b is not returned without its call
+	_, err = container.Get(di.StringRef("b"))
+	assertErrorType(t, err, di.ServiceBuildError)
+	assert.Contains(t, err.Error(), "a failed")
+}
+
+func TestServiceDef_Call_WaitsForConcurrentBuild(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("a")).
+			Provider(func() *cyclicA {
+				close(started)
+				<-release
+
+				return &cyclicA{} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("b")).
+			Provider(func() *cyclicB { return &cyclicB{} }). //nolint:exhaustivestruct
+			Call("SetA", di.ServiceArg(di.StringRef("a"))),
+	)
+
+	go func() {
+		_, _ = container.Get(di.StringRef("a"))
+	}()
+
+	<-started
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+
+	// b is returned once a was built by the other request and its call was executed.
+	b, err := di.Get[*cyclicB](container, di.StringRef("b"))
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, b.a) {
+		assert.Same(t, di.MustGet[*cyclicA](container, di.StringRef("a")), b.a)
+	}
+}
+
+func TestServiceDef_Call_CloseOrder(t *testing.T) {
+	var closed []string
+
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("db")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "db", closed: &closed} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("app")).
+			Provider(func() *callRecorder { return &callRecorder{} }). //nolint:exhaustivestruct
+			Call("SetName", di.ServiceMethodCallArg(di.StringRef("db"), "Name")).
+			Disposer(func(_ context.Context, _ interface{}) error {
+				closed = append(closed, "app")
+
+				return nil
+			}),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.NoError(t, container.Close(context.Background()))
+
+	// the service using db in a call is disposed before db
+	assert.Equal(t, []string{"app", "db"}, closed)
+}
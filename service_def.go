@@ -2,6 +2,8 @@ package di
 
 import (
 	"fmt"
+	"reflect"
+	"sync"
 )
 
 // ServiceDef is a definition of a service
@@ -13,7 +15,53 @@ type ServiceDef struct {
 	options  *serviceOptions
 	provider interface{}
 	args     []ServiceDefArg
+	calls    []methodCall
 	tags     []fmt.Stringer
+	disposer DisposerFunc
+
+	// mu guards instance, pending and state
+	mu sync.Mutex
+	// pending is the build that is currently in progress
+	pending *pendingBuild
+	// state is the lifecycle state of the instance
+	state serviceState
+}
+
+// pendingBuild holds the result of a service build that all concurrent requests of the service wait for.
+// Once the provider returned, the instance is available as constructed while the calls are executed.
+type pendingBuild struct {
+	def *ServiceDef
+	ref fmt.Stringer
+	// active is true while the service is built, see buildTracker
+	active bool
+	// child is the build that was requested by this build and is currently active
+	child *pendingBuild
+	// waitsFor is the build of another goroutine that this build waits for
+	waitsFor *pendingBuild
+	// nested are the services that were built while this build was active
+	nested      []*ServiceDef
+	done        chan struct{}
+	instance    interface{}
+	err         error
+	constructed interface{}
+	// deferred are calls of other services that wait for this build to construct the instance
+	deferred []deferredCall
+}
+
+func newPendingBuild(def *ServiceDef) *pendingBuild {
+	return &pendingBuild{
+		def:         def,
+		ref:         def.ref,
+		active:      false,
+		child:       nil,
+		waitsFor:    nil,
+		nested:      nil,
+		done:        make(chan struct{}),
+		instance:    nil,
+		err:         nil,
+		constructed: nil,
+		deferred:    nil,
+	}
 }
 
 // NewServiceDef creates a new service definition.
@@ -24,7 +72,12 @@ func NewServiceDef(ref fmt.Stringer) *ServiceDef {
 		options:  newServiceOptions(),
 		provider: nil,
 		args:     []ServiceDefArg{},
+		calls:    []methodCall{},
 		tags:     []fmt.Stringer{},
+		disposer: nil,
+		mu:       sync.Mutex{},
+		pending:  nil,
+		state:    serviceBuilt,
 	}
 
 	return i
@@ -60,3 +113,100 @@ func (sd *ServiceDef) Tags(tags ...fmt.Stringer) *ServiceDef {
 
 	return sd
 }
+
+// Disposer defines a function that is called to tear down the service instance on Container.Close.
+// Without a disposer the container calls Close(ctx) error or io.Closer on the instance if implemented.
+func (sd *ServiceDef) Disposer(fn DisposerFunc) *ServiceDef {
+	sd.disposer = fn
+
+	return sd
+}
+
+// Clone returns a copy of the definition without the service instance.
+func (sd *ServiceDef) Clone() *ServiceDef {
+	return &ServiceDef{
+		ref:      sd.ref,
+		instance: nil,
+		options:  sd.options,
+		provider: sd.provider,
+		args:     append([]ServiceDefArg{}, sd.args...),
+		calls:    append([]methodCall{}, sd.calls...),
+		tags:     append([]fmt.Stringer{}, sd.tags...),
+		disposer: sd.disposer,
+		mu:       sync.Mutex{},
+		pending:  nil,
+		state:    serviceBuilt,
+	}
+}
+
+// IsBuilt reports whether the service instance is built or was passed via Set.
+func (sd *ServiceDef) IsBuilt() bool {
+	return sd.getInstance() != nil
+}
+
+// getInstance returns the current service instance or nil if it is not built.
+func (sd *ServiceDef) getInstance() interface{} {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.instance
+}
+
+// takeInstance returns the current service instance and resets it, so the service is built again on request.
+func (sd *ServiceDef) takeInstance() interface{} {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	instance := sd.instance
+	sd.instance = nil
+
+	return instance
+}
+
+// getState returns the lifecycle state of the instance.
+func (sd *ServiceDef) getState() serviceState {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.state
+}
+
+func (sd *ServiceDef) setState(state serviceState) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	sd.state = state
+}
+
+// producedType returns the type of the service instance if it can be known without building the service.
+// This is the type of an instance passed via Set or the first return type of the provider function.
+func (sd *ServiceDef) producedType() reflect.Type {
+	if instance := sd.getInstance(); instance != nil {
+		return reflect.TypeOf(instance)
+	}
+
+	if sd.provider == nil {
+		return nil
+	}
+
+	providerType := reflect.TypeOf(sd.provider)
+	if providerType.Kind() != reflect.Func || providerType.NumOut() == 0 {
+		return nil
+	}
+
+	return providerType.Out(0)
+}
+
+// lifetime returns the name of the lifetime that is defined by the service options.
+func (sd *ServiceDef) lifetime() string {
+	switch {
+	case sd.options.scoped:
+		return LifetimeScoped
+	case sd.options.alwaysRebuild:
+		return LifetimeTransient
+	case sd.options.buildOnFirstRequest:
+		return LifetimeLazy
+	default:
+		return LifetimeSingleton
+	}
+}
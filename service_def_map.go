@@ -37,7 +37,25 @@ func (rm *ServiceDefMap) Store(key fmt.Stringer, value *ServiceDef) {
 	rm.mu.Unlock()
 }
 
+// LoadOrStore returns the existing value for the key if present. Otherwise, it stores and returns the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (rm *ServiceDefMap) LoadOrStore(key fmt.Stringer, value *ServiceDef) (actual *ServiceDef, loaded bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if actual, loaded = rm.internal[key]; loaded {
+		return actual, loaded
+	}
+
+	rm.internal[key] = value
+
+	return value, false
+}
+
 func (rm *ServiceDefMap) Count() int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
 	return len(rm.internal)
 }
 
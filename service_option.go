@@ -7,6 +7,8 @@ type ServiceOption func(so *serviceOptions)
 type serviceOptions struct {
 	buildOnFirstRequest bool
 	alwaysRebuild       bool
+	autowire            bool
+	scoped              bool
 }
 
 // newServiceOptions returns a serviceOptions instance with defaults.
@@ -14,6 +16,8 @@ func newServiceOptions() *serviceOptions {
 	return &serviceOptions{
 		buildOnFirstRequest: false,
 		alwaysRebuild:       false,
+		autowire:            false,
+		scoped:              false,
 	}
 }
 
@@ -31,3 +35,19 @@ func BuildAlwaysRebuild() ServiceOption {
 		opts.alwaysRebuild = true
 	}
 }
+
+// Autowire option resolves all provider parameters that are not covered by Args by type.
+// Each parameter is injected with the single registered service whose type is assignable to the parameter type.
+func Autowire() ServiceOption {
+	return func(opts *serviceOptions) {
+		opts.autowire = true
+	}
+}
+
+// Scoped defines that a service is built once per scope created with Container.NewScope.
+// Scoped services cannot be requested from the root container.
+func Scoped() ServiceOption {
+	return func(opts *serviceOptions) {
+		opts.scoped = true
+	}
+}
@@ -0,0 +1,291 @@
+package di
+
+import (
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"reflect"
+	"strings"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// structField describes a struct field that is injected.
+type structField struct {
+	index  int
+	arg    ServiceDefArg
+	tagged bool
+}
+
+// Struct defines a provider that creates a copy of given struct and injects all fields with a di tag.
+// The prototype is either a struct or a pointer to a struct, which is the type of the service as well.
+// Fields without a di tag keep the value of the prototype. Struct replaces the provider and the args of the
+// definition. The following tags are supported:
+//
+//	Mailer   Mailer          `di:"service=mailer"`              // ServiceArg
+//	Tracer   Tracer          `di:"service=tracer,optional"`     // OptionalServiceArg
+//	Host     string          `di:"param=smtp.host"`             // ParamArg
+//	Port     int             `di:"param=smtp.port,default=25"`  // ParamArgWithDefault
+//	User     string          `di:"param=smtp.user,optional"`    // OptionalParamArg
+//	Handlers []http.Handler  `di:"tagged=handler|api"`          // ServicesByTagsArg
+//	Ctx      context.Context `di:"context"`                     // ContextArg, also "container" and "eventbus"
+//
+// The default value is the rest of the tag, so it may contain commas. Tagged fields must be slices, the services
+// are converted to the element type of the slice. Service and tag names match refs of any type by their string
+// representation, so services registered with custom ref types can be injected as well.
+func (sd *ServiceDef) Struct(prototype interface{}) *ServiceDef {
+	provider, args, err := structProvider(prototype)
+	if err != nil {
+		err = z.WrapWithOpts(err, fmt.Sprintf("invalid struct %T", prototype), z.WithType(StructInjectionError))
+		sd.provider = func(err error) (interface{}, error) { return nil, err }
+		sd.args = []ServiceDefArg{&invalidArg{err: err}}
+
+		return sd
+	}
+
+	sd.provider, sd.args = provider, args
+
+	return sd
+}
+
+// ProvideStruct creates a new service definition that injects the fields of a new *T, see ServiceDef.Struct.
+func ProvideStruct[T any](ref fmt.Stringer) *ServiceDef {
+	return NewServiceDef(ref).Struct(new(T))
+}
+
+// structProvider creates a provider function that takes the values of all injected fields as parameters.
+func structProvider(prototype interface{}) (interface{}, []ServiceDefArg, error) {
+	serviceType := reflect.TypeOf(prototype)
+	if serviceType == nil {
+		return nil, nil, z.New("prototype is nil")
+	}
+
+	structType := serviceType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return nil, nil, z.Newf("%s is not a struct", serviceType)
+	}
+
+	fields, in, err := structFields(structType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	initial := reflect.Indirect(reflect.ValueOf(prototype))
+	if !initial.IsValid() {
+		initial = reflect.Zero(structType)
+	}
+
+	providerType := reflect.FuncOf(in, []reflect.Type{serviceType, errorType}, false)
+
+	provider := reflect.MakeFunc(providerType, func(values []reflect.Value) []reflect.Value {
+		instance := reflect.New(structType)
+		instance.Elem().Set(initial)
+
+		for i, field := range fields {
+			target := instance.Elem().Field(field.index)
+			value := values[i]
+
+			if field.tagged {
+				var err error
+				if value, err = convertTaggedServices(value, target.Type()); err != nil {
+					return []reflect.Value{reflect.Zero(serviceType), errorValue(z.Wrapf(err,
+						"cannot inject field %s", structType.Field(field.index).Name,
+					))}
+				}
+			}
+
+			target.Set(value)
+		}
+
+		if serviceType.Kind() != reflect.Ptr {
+			return []reflect.Value{instance.Elem(), reflect.Zero(errorType)}
+		}
+
+		return []reflect.Value{instance, reflect.Zero(errorType)}
+	})
+
+	args := make([]ServiceDefArg, 0, len(fields))
+	for _, field := range fields {
+		args = append(args, field.arg)
+	}
+
+	return provider.Interface(), args, nil
+}
+
+// structFields returns the injected fields of given struct type and the provider parameter types for them.
+func structFields(structType reflect.Type) (fields []structField, in []reflect.Type, err error) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		tag, ok := field.Tag.Lookup("di")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		if field.PkgPath != "" {
+			return nil, nil, z.Newf("field %s is not exported", field.Name)
+		}
+
+		arg, tagged, err := parseStructTag(tag)
+		if err != nil {
+			return nil, nil, z.Wrapf(err, "invalid tag of field %s", field.Name)
+		}
+
+		paramType := field.Type
+
+		if tagged {
+			if field.Type.Kind() != reflect.Slice {
+				return nil, nil, z.Newf("field %s is tagged but not a slice", field.Name)
+			}
+
+			// services by tags are injected as []interface{} and converted to the field type afterwards
+			paramType = reflect.TypeOf([]interface{}{})
+		}
+
+		fields = append(fields, structField{index: i, arg: arg, tagged: tagged})
+		in = append(in, paramType)
+	}
+
+	return fields, in, nil
+}
+
+// parseStructTag returns the arg for a di struct tag and whether it injects services by tags.
+func parseStructTag(tag string) (ServiceDefArg, bool, error) {
+	var (
+		def        string
+		hasDefault bool
+		optional   bool
+	)
+
+	// the default value is the rest of the tag
+	if i := strings.Index(tag, ",default="); i >= 0 {
+		def, hasDefault = tag[i+len(",default="):], true
+		tag = tag[:i]
+	}
+
+	parts := strings.Split(tag, ",")
+
+	for _, modifier := range parts[1:] {
+		if strings.TrimSpace(modifier) != "optional" {
+			return nil, false, z.Newf("unknown modifier %q", modifier)
+		}
+
+		optional = true
+	}
+
+	kind, value, _ := strings.Cut(strings.TrimSpace(parts[0]), "=")
+
+	if hasDefault && kind != "param" {
+		return nil, false, z.New("default is only supported for param")
+	}
+
+	switch kind {
+	case "service":
+		if optional {
+			return OptionalServiceArg(nameRef(value)), false, nil
+		}
+
+		return ServiceArg(nameRef(value)), false, nil
+	case "param":
+		switch {
+		case hasDefault:
+			return ParamArgWithDefault(value, def), false, nil
+		case optional:
+			return OptionalParamArg(value), false, nil
+		default:
+			return ParamArg(value), false, nil
+		}
+	case "tagged":
+		names := strings.Split(value, "|")
+
+		tags := make([]fmt.Stringer, 0, len(names))
+		for _, name := range names {
+			tags = append(tags, nameRef(name))
+		}
+
+		return ServicesByTagsArg(tags), true, nil
+	case "context":
+		return ContextArg(), false, nil
+	case "container":
+		return ContainerArg(), false, nil
+	case "eventbus":
+		return EventBusArg(), false, nil
+	}
+
+	return nil, false, z.Newf("unknown injection %q", kind)
+}
+
+// convertTaggedServices converts the []interface{} of services by tags into a slice of given type.
+func convertTaggedServices(services reflect.Value, sliceType reflect.Type) (reflect.Value, error) {
+	converted := reflect.MakeSlice(sliceType, 0, services.Len())
+
+	for i := 0; i < services.Len(); i++ {
+		service := services.Index(i).Elem()
+		if !service.IsValid() {
+			converted = reflect.Append(converted, reflect.Zero(sliceType.Elem()))
+
+			continue
+		}
+
+		if !service.Type().AssignableTo(sliceType.Elem()) {
+			return reflect.Value{}, z.NewWithOpts(
+				fmt.Sprintf("service of type %s is not assignable to %s", service.Type(), sliceType.Elem()),
+				z.WithType(ServiceTypeMismatchError),
+			)
+		}
+
+		converted = reflect.Append(converted, service)
+	}
+
+	return converted, nil
+}
+
+func errorValue(err error) reflect.Value {
+	return reflect.ValueOf(&err).Elem()
+}
+
+// invalidArg is used for definitions that are invalid. It reports its error on evaluation and validation.
+type invalidArg struct {
+	err error
+}
+
+func (a *invalidArg) Evaluate(_ *Container) (interface{}, error) {
+	return nil, a.err
+}
+
+func (a *invalidArg) validate(_ *Container) []error {
+	return []error{a.err}
+}
+
+// nameRef refers to a service, an alias or a tag by the name given in a di struct tag.
+// It matches registered refs of any type whose string representation is the name.
+type nameRef string
+
+func (r nameRef) String() string {
+	return string(r)
+}
+
+// resolveNameRef returns the registered service or alias ref that matches a nameRef.
+// Other refs and names without a match are returned unchanged.
+func (c *Container) resolveNameRef(ref fmt.Stringer) fmt.Stringer {
+	name, ok := ref.(nameRef)
+	if !ok {
+		return ref
+	}
+
+	candidates := c.aliasRefs()
+	for key := range c.allServiceDefs() {
+		candidates = append(candidates, key)
+	}
+
+	for _, candidate := range sortRefs(candidates) {
+		if candidate.String() == string(name) {
+			return candidate
+		}
+	}
+
+	return ref
+}
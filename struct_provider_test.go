@@ -0,0 +1,169 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type mailHandler struct {
+	Ctx      context.Context `di:"context"`
+	Mailer   *closeRecorder  `di:"service=mailer"`
+	Tracer   *closeRecorder  `di:"service=tracer,optional"`
+	Host     string          `di:"param=smtp.host"`
+	Port     int             `di:"param=smtp.port,default=25"`
+	User     string          `di:"param=smtp.user,optional"`
+	Handlers []TestInterface `di:"tagged=handler|api"`
+	Name     string
+	Ignored  string `di:"-"`
+}
+
+func newStructContainer(t *testing.T) *di.Container {
+	t.Helper()
+
+	c := newParamContainer(t, map[string]interface{}{"smtp.host": "localhost"})
+	c.Register(
+		di.NewServiceDef(di.StringRef("mailer")).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "mailer"} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(di.StringRef("handler")).
+			Provider(NewTestService1).
+			Args(di.ContextArg(), di.ContainerArg(), di.InterfaceArg(true), di.InterfaceArg("handler")).
+			Tags(di.StringRef("handler"), di.StringRef("api")),
+	)
+
+	return c
+}
+
+func TestServiceDef_Struct(t *testing.T) {
+	c := newStructContainer(t)
+	c.Register(
+		di.NewServiceDef(di.StringRef("mail")).Struct(&mailHandler{Name: "prototype"}), //nolint:exhaustivestruct
+		di.NewServiceDef(di.StringRef("mail.value")).Struct(mailHandler{}),             //nolint:exhaustivestruct
+	)
+
+	assert.NoError(t, c.Validate())
+
+	h, err := di.Get[*mailHandler](c, di.StringRef("mail"))
+	assert.NoError(t, err)
+	assert.Equal(t, c.GetContext(), h.Ctx)
+	assert.Equal(t, "mailer", h.Mailer.Name())
+	assert.Nil(t, h.Tracer)
+	assert.Equal(t, "localhost", h.Host)
+	assert.Equal(t, 25, h.Port)
+	assert.Equal(t, "", h.User)
+	assert.Len(t, h.Handlers, 1)
+	assert.Equal(t, "prototype", h.Name)
+
+	v, err := di.Get[mailHandler](c, di.StringRef("mail.value"))
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", v.Host)
+}
+
+func TestProvideStruct(t *testing.T) {
+	c := newStructContainer(t)
+	c.Register(di.ProvideStruct[mailHandler](di.StringRef("mail")))
+
+	h, err := di.Get[*mailHandler](c, di.StringRef("mail"))
+	assert.NoError(t, err)
+	assert.Equal(t, "mailer", h.Mailer.Name())
+
+	// each build creates a new struct
+	c.Register(di.ProvideStruct[mailHandler](di.StringRef("transient")).Opts(di.BuildAlwaysRebuild()))
+	first := di.MustGet[*mailHandler](c, di.StringRef("transient"))
+	assert.NotSame(t, first, di.MustGet[*mailHandler](c, di.StringRef("transient")))
+}
+
+func TestServiceDef_Struct_Errors(t *testing.T) {
+	type unexported struct {
+		mailer *closeRecorder `di:"service=mailer"`
+	}
+
+	type unknownInjection struct {
+		Mailer *closeRecorder `di:"services=mailer"`
+	}
+
+	type unknownModifier struct {
+		Mailer *closeRecorder `di:"service=mailer,lazy"`
+	}
+
+	type invalidDefault struct {
+		Mailer *closeRecorder `di:"service=mailer,default=foo"`
+	}
+
+	type taggedNoSlice struct {
+		Handler TestInterface `di:"tagged=handler"`
+	}
+
+	for _, prototype := range []interface{}{
+		nil,
+		"foo",
+		&unexported{},       //nolint:exhaustivestruct
+		&unknownInjection{}, //nolint:exhaustivestruct
+		&unknownModifier{},  //nolint:exhaustivestruct
+		&invalidDefault{},   //nolint:exhaustivestruct
+		&taggedNoSlice{},    //nolint:exhaustivestruct
+	} {
+		t.Run(fmt.Sprintf("%T", prototype), func(t *testing.T) {
+			c := newStructContainer(t)
+			c.Register(di.NewServiceDef(di.StringRef("invalid")).Struct(prototype))
+
+			_, err := c.Get(di.StringRef("invalid"))
+			assertErrorType(t, err, di.StructInjectionError)
+
+			err = c.Validate()
+			assertErrorType(t, err, di.ContainerValidationError)
+			assert.Contains(t, err.Error(), "invalid struct")
+		})
+	}
+}
+
+func TestServiceDef_Struct_TaggedTypeMismatch(t *testing.T) {
+	type handlers struct {
+		Handlers []*closeRecorder `di:"tagged=handler"`
+	}
+
+	c := newStructContainer(t)
+	c.Register(di.NewServiceDef(di.StringRef("handlers")).Struct(&handlers{})) //nolint:exhaustivestruct
+
+	_, err := c.Get(di.StringRef("handlers"))
+	assertErrorType(t, err, di.ServiceTypeMismatchError)
+	assert.Contains(t, err.Error(), "cannot inject field Handlers")
+}
+
+// structRef is a custom ref type like the ones applications define for their services.
+type structRef int
+
+const (
+	structRefMailer structRef = iota
+	structRefHandler
+)
+
+func (r structRef) String() string {
+	return [...]string{"mailer", "handler"}[r]
+}
+
+func TestServiceDef_Struct_CustomRefTypes(t *testing.T) {
+	c := newParamContainer(t, map[string]interface{}{"smtp.host": "localhost"})
+	c.Register(
+		di.NewServiceDef(structRefMailer).
+			Provider(func() *closeRecorder {
+				return &closeRecorder{name: "mailer"} //nolint:exhaustivestruct
+			}),
+		di.NewServiceDef(structRefHandler).
+			Provider(NewTestService1).
+			Args(di.ContextArg(), di.ContainerArg(), di.InterfaceArg(true), di.InterfaceArg("handler")).
+			Tags(structRefHandler, di.StringRef("api")),
+		di.ProvideStruct[mailHandler](di.StringRef("mail")),
+	)
+
+	assert.NoError(t, c.Validate())
+
+	h, err := di.Get[*mailHandler](c, di.StringRef("mail"))
+	assert.NoError(t, err)
+	assert.Equal(t, "mailer", h.Mailer.Name())
+	assert.Len(t, h.Handlers, 1)
+}
@@ -0,0 +1,140 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	z "github.com/dtomasi/zerrors"
+	"github.com/hashicorp/go-multierror"
+	"reflect"
+)
+
+// Get returns the service registered for ref as T.
+// A ServiceTypeMismatchError is returned if the service instance does not satisfy T.
+func Get[T any](c *Container, ref fmt.Stringer) (T, error) {
+	var typed T
+
+	instance, err := c.Get(ref)
+	if err != nil {
+		return typed, err
+	}
+
+	return assertType[T](ref, instance)
+}
+
+// MustGet returns the service registered for ref as T or panics on error.
+func MustGet[T any](c *Container, ref fmt.Stringer) T {
+	typed, err := Get[T](c, ref)
+	if err != nil {
+		panic(err)
+	}
+
+	return typed
+}
+
+// FindByTags finds all service instances with given tags and returns them as a slice of T.
+// A ServiceTypeMismatchError is reported for each instance that does not satisfy T. Like Container.FindByTags,
+// all errors are collected and returned together.
+func FindByTags[T any](c *Container, tags []fmt.Stringer) ([]T, error) {
+	var errs error
+
+	refs := c.findRefsByTags(tags)
+	typed := make([]T, 0, len(refs))
+
+	for _, ref := range refs {
+		instance, err := c.get(ref)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+
+			continue
+		}
+
+		typedInstance, err := assertType[T](ref, instance)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+
+			continue
+		}
+
+		typed = append(typed, typedInstance)
+	}
+
+	if errs != nil {
+		return nil, findByTagsError(tags, errs)
+	}
+
+	return typed, nil
+}
+
+// Bind binds the type T, usually an interface, to the service ref. See Container.Bind.
+func Bind[T any](c *Container, ref fmt.Stringer) *Container {
+	return c.Bind(typeOf[T](), ref)
+}
+
+// GetByType returns the service that is bound to the type T as T.
+func GetByType[T any](c *Container) (T, error) {
+	var typed T
+
+	instance, err := c.GetByType(typeOf[T]())
+	if err != nil {
+		return typed, err
+	}
+
+	return assertType[T](typeRef{t: typeOf[T]()}, instance)
+}
+
+// Provide creates a new service definition with a provider that is type checked at compile time.
+// The provider receives the context and the container to fetch its dependencies, for example using Get. The
+// container is a view that requests services as part of the build and must not be kept by the service.
+//
+// Dependencies fetched inside of the provider are not known before it is called, so they are not part of the
+// dependency graph: Validate does not check them, Graph does not show them, and Build and Close do not order
+// services by them. Circular requests between such providers are only detected at runtime and fail with a
+// CircularDependencyError. Use Provide0 to Provide3 to declare dependencies explicitly.
+func Provide[T any](ref fmt.Stringer, provider func(ctx context.Context, c *Container) (T, error)) *ServiceDef {
+	return NewServiceDef(ref).
+		Provider(provider).
+		Args(ContextArg(), &containerArg{inBuild: true})
+}
+
+// Provide0 creates a new service definition with a provider without args that is type checked at compile time.
+func Provide0[T any](ref fmt.Stringer, provider func() (T, error)) *ServiceDef {
+	return NewServiceDef(ref).Provider(provider)
+}
+
+// Provide1 creates a new service definition with a provider that is type checked at compile time and receives
+// the value of the declared arg. Unlike dependencies fetched inside of a Provide provider, services injected via
+// ServiceArg are part of the dependency graph.
+func Provide1[T, A any](ref fmt.Stringer, provider func(A) (T, error), a ServiceDefArg) *ServiceDef {
+	return NewServiceDef(ref).Provider(provider).Args(a)
+}
+
+// Provide2 is like Provide1 for providers with two args.
+func Provide2[T, A, B any](ref fmt.Stringer, provider func(A, B) (T, error), a, b ServiceDefArg) *ServiceDef {
+	return NewServiceDef(ref).Provider(provider).Args(a, b)
+}
+
+// Provide3 is like Provide1 for providers with three args.
+func Provide3[T, A, B, C any](
+	ref fmt.Stringer,
+	provider func(A, B, C) (T, error),
+	a, b, c ServiceDefArg,
+) *ServiceDef {
+	return NewServiceDef(ref).Provider(provider).Args(a, b, c)
+}
+
+func assertType[T any](ref fmt.Stringer, instance interface{}) (T, error) {
+	typed, ok := instance.(T)
+	if !ok {
+		return typed, z.NewWithOpts(
+			fmt.Sprintf("service %s is of type %T, expected %s", ref, instance, typeOf[T]()),
+			z.WithType(ServiceTypeMismatchError),
+		)
+	}
+
+	return typed, nil
+}
+
+// typeOf returns the reflect.Type of T. Unlike reflect.TypeOf it works for interface types.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
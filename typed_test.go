@@ -0,0 +1,164 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	container, err := BuildContainer()
+	assert.NoError(t, err)
+
+	t1, err := di.Get[*TestService1](container, di.StringRef("TestService1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", t1.TestString())
+
+	iface, err := di.Get[TestInterface](container, di.StringRef("TestService2"))
+	assert.NoError(t, err)
+	assert.True(t, iface.True())
+
+	_, err = di.Get[*TestService2](container, di.StringRef("TestService1"))
+	assertErrorType(t, err, di.ServiceTypeMismatchError)
+	assert.Contains(t, err.Error(), "expected *di_test.TestService2")
+
+	_, err = di.Get[*TestService1](container, di.StringRef("not-existing"))
+	assertErrorType(t, err, di.ServiceNotFoundError)
+}
+
+func TestMustGet(t *testing.T) {
+	container, err := BuildContainer()
+	assert.NoError(t, err)
+
+	service := di.MustGet[*TestService1](container, di.StringRef("TestService1"))
+	assert.IsType(t, &TestService1{}, service) //nolint:exhaustivestruct
+	assert.Panics(t, func() {
+		di.MustGet[string](container, di.StringRef("TestService1"))
+	})
+}
+
+func TestFindByTags(t *testing.T) {
+	container, err := BuildContainer()
+	assert.NoError(t, err)
+
+	services, err := di.FindByTags[TestInterface](container, []fmt.Stringer{di.StringRef("test")})
+	assert.NoError(t, err)
+	assert.Len(t, services, 1)
+
+	_, err = di.FindByTags[string](container, []fmt.Stringer{di.StringRef("test")})
+	assertErrorType(t, err, di.ServiceTypeMismatchError)
+}
+
+func TestFindByTags_CollectsErrors(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("first")).
+			Provider(func() int { return 1 }).
+			Tags(di.StringRef("number")),
+		di.NewServiceDef(di.StringRef("second")).
+			Provider(func() int { return 2 }).
+			Tags(di.StringRef("number")),
+	)
+
+	_, err := di.FindByTags[string](container, []fmt.Stringer{di.StringRef("number")})
+	assertErrorType(t, err, di.ServiceTypeMismatchError)
+	assert.Contains(t, err.Error(), "2 errors occurred")
+	assert.Contains(t, err.Error(), "service first is of type int")
+	assert.Contains(t, err.Error(), "service second is of type int")
+}
+
+func TestProvide(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.Provide(di.StringRef("TestService1"), func(ctx context.Context, c *di.Container) (*TestService1, error) {
+			return NewTestService1(ctx, c, true, "bar"), nil
+		}),
+		di.Provide(di.StringRef("TestService2"), func(ctx context.Context, c *di.Container) (TestInterface, error) {
+			t1, err := di.Get[*TestService1](c, di.StringRef("TestService1"))
+			if err != nil {
+				return nil, err
+			}
+
+			return NewTestService2(t1, nil, t1.True(), t1.TestString(), ""), nil
+		}),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.Equal(t, "bar", di.MustGet[*TestService2](container, di.StringRef("TestService2")).TestString())
+}
+
+func TestProvide_Cycle(t *testing.T) {
+	container := newProvideCycleContainer()
+
+	// dependencies fetched inside of providers are not known to the validation
+	assert.NoError(t, container.Validate())
+
+	withinTimeout(t, func() {
+		_, err := di.Get[string](container, di.StringRef("a"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "[CircularDependencyError]")
+		assert.Contains(t, err.Error(), "a -> b -> a")
+	})
+}
+
+func TestProvideN(t *testing.T) {
+	container := di.NewServiceContainer(di.WithParameterProvider(&ParameterProviderMock{}))
+	container.Register(
+		di.Provide0(di.StringRef("salutation"), func() (string, error) { return "Hello", nil }),
+		di.Provide1(di.StringRef("name"), func(name string) (string, error) {
+			return name, nil
+		}, di.InterfaceArg("John")),
+		di.Provide2(di.StringRef("greeting"), func(salutation string, name string) (string, error) {
+			return salutation + ", " + name, nil
+		}, di.ServiceArg(di.StringRef("salutation")), di.ServiceArg(di.StringRef("name"))),
+		di.Provide3(di.StringRef("message"), func(greeting string, param string, excited bool) (string, error) {
+			if excited {
+				return greeting + " " + param + "!", nil
+			}
+
+			return greeting, nil
+		}, di.ServiceArg(di.StringRef("greeting")), di.ParamArg("foo.bar.baz"), di.InterfaceArg(true)),
+	)
+
+	assert.NoError(t, container.Validate())
+	assert.ElementsMatch(t,
+		[]fmt.Stringer{di.StringRef("salutation"), di.StringRef("name")},
+		container.Dependencies(di.StringRef("greeting")),
+	)
+
+	assert.NoError(t, container.Build())
+	assert.Equal(t, "Hello, John foo!", di.MustGet[string](container, di.StringRef("message")))
+}
+
+func TestProvideN_ValidatesDeclaredArgs(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.Provide1(di.StringRef("a"), func(b string) (string, error) {
+			return b, nil
+		}, di.ServiceArg(di.StringRef("b"))),
+		di.Provide1(di.StringRef("b"), func(a string) (string, error) {
+			return a, nil
+		}, di.ServiceArg(di.StringRef("a"))),
+	)
+
+	// unlike with Provide the dependencies are declared, so the cycle is found without calling the providers
+	err := container.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "[CircularDependencyError]")
+}
+
+func ExampleMustGet() {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("salutation")).
+			Provider(func() string { return "Hello, John" }),
+	)
+
+	// MustGet returns the service as the requested type, so no type assertion is required
+	salutation := di.MustGet[string](container, di.StringRef("salutation"))
+	fmt.Println(salutation)
+
+	// Output: Hello, John
+}
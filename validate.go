@@ -0,0 +1,181 @@
+package di
+
+import (
+	"fmt"
+	"github.com/dtomasi/di/internal/pkg/utils"
+	z "github.com/dtomasi/zerrors"
+	"github.com/hashicorp/go-multierror"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// validatingArg is implemented by arguments that can check their target without evaluating it.
+type validatingArg interface {
+	validate(c *Container) []error
+}
+
+// typedArg is implemented by arguments whose value type can be known without evaluating them.
+// staticType returns nil if the type is unknown.
+type typedArg interface {
+	staticType(c *Container) reflect.Type
+}
+
+// Validate checks the wiring of all registered services without calling any provider.
+// It checks that providers are functions whose parameters match the defined args, that all referenced services
+// are registered, that argument types match where they are known, that there are no circular dependencies and
+// that all parameters can be resolved and that all aliases point to registered services.
+// All problems are returned at once.
+func (c *Container) Validate() (err error) {
+	defer z.WrapPtrWithOpts(&err, "container validation failed", z.WithType(ContainerValidationError))
+
+	defs := c.allServiceDefs()
+
+	refs := make([]fmt.Stringer, 0, len(defs))
+	for ref := range defs {
+		refs = append(refs, ref)
+	}
+
+	for _, alias := range sortRefs(c.aliasRefs()) {
+		for _, aliasErr := range c.validateAlias(alias) {
+			err = multierror.Append(err, z.Wrapf(aliasErr, "invalid alias %s", alias))
+		}
+	}
+
+	reportedCycles := map[string]bool{}
+
+	for _, ref := range sortRefs(refs) {
+		for _, defErr := range c.validateServiceDef(defs[ref]) {
+			err = multierror.Append(err, z.Wrapf(defErr, "invalid service %s", ref))
+		}
+
+		// a cycle is reachable from all services depending on it, so it is reported only once.
+		if cycle := c.findCycle(ref); cycle != nil && !reportedCycles[cycleKey(cycle)] {
+			reportedCycles[cycleKey(cycle)] = true
+
+			err = multierror.Append(err, z.NewWithOpts(
+				fmt.Sprintf("circular dependency detected: %s", formatRefChain(cycle)),
+				z.WithType(CircularDependencyError),
+			))
+		}
+	}
+
+	return err
+}
+
+// cycleKey returns an identifier for a cycle that does not depend on the ref the cycle was found from.
+func cycleKey(cycle []fmt.Stringer) string {
+	names := refNames(cycle[1:])
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+func (c *Container) validateServiceDef(def *ServiceDef) (errs []error) {
+	if def.provider == nil {
+		if def.getInstance() == nil {
+			errs = append(errs, z.NewWithOpts("provider missing", z.WithType(ProviderMissingError)))
+		}
+
+		return errs
+	}
+
+	providerType := reflect.TypeOf(def.provider)
+	if providerType.Kind() != reflect.Func {
+		return append(errs, z.NewWithOpts("provider not a function", z.WithType(CallableNotAFuncError)))
+	}
+
+	if providerType.NumOut() == 0 || providerType.NumOut() > 2 { //nolint:gomnd
+		errs = append(errs, z.NewWithOpts(
+			fmt.Sprintf("provider must have 1 or 2 return values (interface{}, error). Got %d",
+				providerType.NumOut(),
+			),
+			z.WithType(CallableToManyReturnValuesError),
+		))
+	}
+
+	args, err := c.resolveArgs(def)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if providerType.NumIn() != len(args) {
+		errs = append(errs, z.NewWithOpts(
+			fmt.Sprintf("provider expects %d args got %d", providerType.NumIn(), len(args)),
+			z.WithType(CallableArgCountMismatchError),
+		))
+	}
+
+	errs = append(errs, c.validateArgs(args, providerType)...)
+
+	errs = append(errs, c.validateCalls(def)...)
+
+	return append(errs, c.validateDecorators(def)...)
+}
+
+// validateArgs validates given args against the parameters of a callable type.
+// If the callable type is nil, only the args themselves are validated.
+func (c *Container) validateArgs(args []ServiceDefArg, callableType reflect.Type) (errs []error) {
+	for i, arg := range args {
+		if v, ok := arg.(validatingArg); ok {
+			errs = append(errs, v.validate(c)...)
+		}
+
+		if callableType == nil || i >= callableType.NumIn() {
+			continue
+		}
+
+		if err := c.validateArgType(arg, callableType.In(i)); err != nil {
+			errs = append(errs, z.Wrapf(err, "invalid arg %d", i))
+		}
+	}
+
+	return errs
+}
+
+// validateArgType checks that the type of the arg matches the parameter type if the arg type can be known.
+// This follows the type check that is done while calling a provider.
+func (c *Container) validateArgType(arg ServiceDefArg, paramType reflect.Type) error {
+	t, ok := arg.(typedArg)
+	if !ok {
+		return nil
+	}
+
+	return validateType(t.staticType(c), paramType)
+}
+
+// validateType checks that a value of given type can be passed to a parameter of paramType.
+// A nil or interface type is accepted, as the concrete type is only known at runtime.
+func validateType(argType reflect.Type, paramType reflect.Type) error {
+	if argType == nil || argType.Kind() == reflect.Interface {
+		// the concrete type is only known at runtime.
+		return nil
+	}
+
+	if utils.GetType(argType) == utils.GetType(paramType) || argType.AssignableTo(paramType) {
+		return nil
+	}
+
+	return z.NewWithOpts(
+		fmt.Sprintf("expected %s got %s", paramType, argType),
+		z.WithType(CallableArgTypeMismatchError),
+	)
+}
+
+// validateServiceRef checks that a referenced service is registered.
+func (c *Container) validateServiceRef(ref fmt.Stringer) error {
+	if _, ok := c.loadServiceDef(ref); !ok {
+		return z.NewWithOpts(fmt.Sprintf("referenced service %s not found", ref), z.WithType(ServiceNotFoundError))
+	}
+
+	return nil
+}
+
+// staticServiceType returns the type of the referenced service if it is known.
+func (c *Container) staticServiceType(ref fmt.Stringer) reflect.Type {
+	if def, ok := c.loadServiceDef(ref); ok {
+		return c.serviceType(def)
+	}
+
+	return nil
+}
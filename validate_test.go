@@ -0,0 +1,80 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/dtomasi/di"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContainer_Validate(t *testing.T) {
+	container := di.NewServiceContainer(di.WithParameterProvider(&ParameterProviderMock{}))
+	container.Register(
+		di.NewServiceDef(di.StringRef("TestService1")).
+			Provider(func(ctx context.Context, c *di.Container, isTrue bool, testString string) *TestService1 {
+				panic("providers must not be called while validating")
+			}).
+			Args(di.ContextArg(), di.ContainerArg(), di.InterfaceArg(true), di.ParamArg("foo.bar.baz")).
+			Tags(di.StringRef("foo")),
+		di.NewServiceDef(di.StringRef("TestService2")).
+			Provider(NewTestService2).
+			Args(
+				di.ServiceArg(di.StringRef("TestService1")),
+				di.ServicesByTagsArg([]fmt.Stringer{di.StringRef("foo")}),
+				di.InterfaceArg(true),
+				di.ParamArg("foo.bar.baz"),
+				di.ServiceMethodCallArg(di.StringRef("TestService1"), "TestFactoryMethod", di.InterfaceArg("foo")),
+			),
+	)
+	container.Set(di.StringRef("instance"), "foo")
+
+	assert.NoError(t, container.Validate())
+}
+
+func TestContainer_Validate_Errors(t *testing.T) {
+	container := di.NewServiceContainer()
+	container.Register(
+		di.NewServiceDef(di.StringRef("no-provider")),
+		di.NewServiceDef(di.StringRef("no-func")).Provider("foo"),
+		di.NewServiceDef(di.StringRef("no-return")).Provider(func() {}),
+		di.NewServiceDef(di.StringRef("arg-count")).
+			Provider(func(s string) string { return s }),
+		di.NewServiceDef(di.StringRef("arg-type")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("TestService1"))),
+		di.NewServiceDef(di.StringRef("not-registered")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceArg(di.StringRef("missing"))),
+		di.NewServiceDef(di.StringRef("param")).
+			Provider(func(s string) string { return s }).
+			Args(di.ParamArg("foo")),
+		di.NewServiceDef(di.StringRef("method")).
+			Provider(func(s string) string { return s }).
+			Args(di.ServiceMethodCallArg(di.StringRef("TestService1"), "NotExisting")),
+		di.NewServiceDef(di.StringRef("TestService1")).
+			Provider(func(s string) *TestService1 { return nil }).
+			Args(di.ServiceArg(di.StringRef("cycle"))),
+		di.NewServiceDef(di.StringRef("cycle")).
+			Provider(func(t1 *TestService1) string { return "" }).
+			Args(di.ServiceArg(di.StringRef("TestService1"))),
+	)
+
+	err := container.Validate()
+	assertErrorType(t, err, di.ContainerValidationError)
+	assert.Contains(t, err.Error(), "9 errors occurred")
+
+	for _, expected := range []string{
+		"invalid service no-provider: [ProviderMissingError]",
+		"invalid service no-func: [CallableNotAFuncError]",
+		"invalid service no-return: [CallableToManyReturnValuesError]: provider must have 1 or 2 return values",
+		"invalid service arg-count: [CallableArgCountMismatchError]: provider expects 1 args got 0",
+		"invalid service arg-type: invalid arg 0: [CallableArgTypeMismatchError]: expected string got *di_test.TestService1",
+		"invalid service not-registered: [ServiceNotFoundError]: referenced service missing not found",
+		"invalid service param: parameter foo cannot be resolved",
+		"invalid service method: [CallableNotAFuncError]: method NotExisting not found on *di_test.TestService1",
+		"[CircularDependencyError]: circular dependency detected: TestService1 -> cycle -> TestService1",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+}
@@ -17,11 +17,30 @@ func _() {
 	_ = x[CallableArgCountMismatchError-6]
 	_ = x[CallableArgTypeMismatchError-7]
 	_ = x[ParamProviderNotDefinedError-8]
+	_ = x[ContainerCloseError-9]
+	_ = x[ServiceDisposeError-10]
+	_ = x[CircularDependencyError-11]
+	_ = x[ServiceTypeMismatchError-12]
+	_ = x[AutowireNoCandidateError-13]
+	_ = x[AutowireAmbiguousError-14]
+	_ = x[DefinitionLoadError-15]
+	_ = x[ProviderNotRegisteredError-16]
+	_ = x[ServiceScopeError-17]
+	_ = x[ContainerValidationError-18]
+	_ = x[ParamNotFoundError-19]
+	_ = x[ParamLoadError-20]
+	_ = x[ParamConversionError-21]
+	_ = x[ParamInterpolationError-22]
+	_ = x[ParamProviderNotWatchableError-23]
+	_ = x[StructInjectionError-24]
+	_ = x[ServiceStartError-25]
+	_ = x[ServiceStopError-26]
+	_ = x[ServiceRunError-27]
 }
 
-const _ErrorType_name = "ContainerBuildErrorServiceNotFoundErrorServiceBuildErrorProviderMissingErrorCallableNotAFuncErrorCallableToManyReturnValuesErrorCallableArgCountMismatchErrorCallableArgTypeMismatchErrorParamProviderNotDefinedError"
+const _ErrorType_name = "ContainerBuildErrorServiceNotFoundErrorServiceBuildErrorProviderMissingErrorCallableNotAFuncErrorCallableToManyReturnValuesErrorCallableArgCountMismatchErrorCallableArgTypeMismatchErrorParamProviderNotDefinedErrorContainerCloseErrorServiceDisposeErrorCircularDependencyErrorServiceTypeMismatchErrorAutowireNoCandidateErrorAutowireAmbiguousErrorDefinitionLoadErrorProviderNotRegisteredErrorServiceScopeErrorContainerValidationErrorParamNotFoundErrorParamLoadErrorParamConversionErrorParamInterpolationErrorParamProviderNotWatchableErrorStructInjectionErrorServiceStartErrorServiceStopErrorServiceRunError"
 
-var _ErrorType_index = [...]uint8{0, 19, 39, 56, 76, 97, 128, 157, 185, 213}
+var _ErrorType_index = [...]uint16{0, 19, 39, 56, 76, 97, 128, 157, 185, 213, 232, 251, 274, 298, 322, 344, 363, 389, 406, 430, 448, 462, 482, 505, 535, 555, 572, 588, 603}
 
 func (i ErrorType) String() string {
 	if i < 0 || i >= ErrorType(len(_ErrorType_index)-1) {
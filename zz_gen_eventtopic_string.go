@@ -9,11 +9,18 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[EventTopicDIReady-0]
+	_ = x[EventTopicServiceBuilding-1]
+	_ = x[EventTopicServiceBuilt-2]
+	_ = x[EventTopicServiceBuildFailed-3]
+	_ = x[EventTopicServiceRequested-4]
+	_ = x[EventTopicContainerClosing-5]
+	_ = x[EventTopicContainerClosed-6]
+	_ = x[EventTopicParamsChanged-7]
 }
 
-const _EventTopic_name = "di:ready"
+const _EventTopic_name = "di:readydi:service:buildingdi:service:builtdi:service:build_faileddi:service:requesteddi:container:closingdi:container:closeddi:params:changed"
 
-var _EventTopic_index = [...]uint8{0, 8}
+var _EventTopic_index = [...]uint8{0, 8, 27, 43, 66, 86, 106, 125, 142}
 
 func (i EventTopic) String() string {
 	if i < 0 || i >= EventTopic(len(_EventTopic_index)-1) {